@@ -1,11 +1,19 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"fmt"
 	"io"
+	"runtime"
+	"strings"
+	"time"
 
+	"github.com/stkisengese/tetris-optimizer/internal/cache"
 	"github.com/stkisengese/tetris-optimizer/internal/parser"
+	"github.com/stkisengese/tetris-optimizer/internal/report"
 	"github.com/stkisengese/tetris-optimizer/internal/solver"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
 )
 
 // AppResult represents the result of running the application
@@ -18,21 +26,134 @@ type AppResult struct {
 // RunApp contains the main application logic, extracted for testing
 func RunApp(args []string, writer io.Writer) AppResult {
 	if len(args) < 2 {
-		fmt.Fprintln(writer, "Usage: go run . <input_file>")
+		printUsage(writer)
 		return AppResult{ExitCode: 1}
 	}
 
-	filename := args[1]
+	switch args[1] {
+	case "generate":
+		return runGenerate(args[2:], writer)
+	case "serve":
+		return runServe(args[2:], writer)
+	case "dimacs":
+		return runDimacs(args[2:], writer)
+	case "coordinate":
+		return runCoordinate(args[2:], writer)
+	case "work":
+		return runWork(args[2:], writer)
+	case "bench":
+		return runBench(args[2:], writer)
+	case "play":
+		return runPlay(args[2:], writer)
+	case "design":
+		return runDesign(args[2:], writer)
+	}
+
+	return runSolve(args[1:], writer)
+}
+
+// printUsage writes the command-line synopsis
+func printUsage(writer io.Writer) {
+	fmt.Fprintln(writer, "Usage: go run . [-input-format auto|blocks|compact|json] [-format text|json] [-stats] [-strategy NAME] [-order NAME] [-time-limit DURATION] [-cache-dir DIR] [-checkpoint FILE [-checkpoint-every DURATION] [-resume]] <input_file>")
+	fmt.Fprintln(writer, "       go run . [flags] [-jobs N] [-format text|jsonl] <input_file|glob>...")
+	fmt.Fprintln(writer, "       go run . generate [flags]")
+	fmt.Fprintln(writer, "       go run . serve [flags]")
+	fmt.Fprintln(writer, "       go run . dimacs [-size N] [-input-format FORMAT] <input_file>")
+	fmt.Fprintln(writer, "       go run . coordinate [-listen ADDR] [flags] <input_file>")
+	fmt.Fprintln(writer, "       go run . work [-connect ADDR] [-name NAME]")
+	fmt.Fprintln(writer, "       go run . bench [-counts N,...] [-strategies NAME,...] [-format markdown|csv|json] [-baseline FILE] [-save FILE]")
+	fmt.Fprintln(writer, "       go run . play [-size N] [-input-format FORMAT] [-strategy NAME] <input_file>")
+	fmt.Fprintln(writer, "       go run . design [-n N | -square K] [-seed N] [-attempts N] [-format text|json] [-out FILE]")
+}
+
+// runSolve parses one or more input files, solves them and prints the
+// solutions
+func runSolve(args []string, writer io.Writer) AppResult {
+	flags := flag.NewFlagSet("tetris-optimizer", flag.ContinueOnError)
+	flags.SetOutput(writer)
+
+	inputFormat := flags.String("input-format", string(parser.FormatAuto), "input format: auto, blocks, compact or json")
+	format := flags.String("format", "text", "output format: text, json or jsonl (one JSON object per file)")
+	showStats := flags.Bool("stats", false, "print piece statistics after the solution")
+	strategy := flags.String("strategy", solver.DefaultStrategy, "search strategy: "+strings.Join(solver.Strategies(), ", "))
+	order := flags.String("order", solver.OrderInput, "piece order: "+strings.Join(solver.Orders, ", "))
+	timeLimit := flags.Duration("time-limit", solver.DefaultTimeLimit, "search time for the heuristic strategy")
+	cacheDir := flags.String("cache-dir", "", "directory caching solutions between runs")
+	checkpointPath := flags.String("checkpoint", "", "file the backtrack search state is saved to periodically")
+	checkpointEvery := flags.Duration("checkpoint-every", time.Minute, "time between checkpoints")
+	resume := flags.Bool("resume", false, "continue the search saved in the -checkpoint file")
+	jobs := flags.Int("jobs", runtime.NumCPU(), "number of files solved at once when given several")
+
+	if err := flags.Parse(args); err != nil {
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	if flags.NArg() == 0 || (*format != "text" && *format != "json" && *format != "jsonl") || (*resume && *checkpointPath == "") || *jobs < 1 {
+		printUsage(writer)
+		return AppResult{ExitCode: 1}
+	}
+
+	inFormat, err := parser.ParseFormat(*inputFormat)
+	if err != nil {
+		printUsage(writer)
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	if _, err := solver.Lookup(*strategy); err != nil {
+		printUsage(writer)
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	settings := solveSettings{
+		inputFormat: inFormat,
+		strategy:    *strategy,
+		order:       *order,
+		timeLimit:   *timeLimit,
+		format:      *format,
+		showStats:   *showStats,
+	}
+	if *cacheDir != "" {
+		disk, err := cache.NewDisk(*cacheDir)
+		if err != nil {
+			fmt.Fprintln(writer, "ERROR")
+			return AppResult{ExitCode: 1, Error: err}
+		}
+		settings.cache = cache.New(disk)
+	}
+
+	// Several files, a glob or JSON Lines output select batch mode
+	if flags.NArg() > 1 || isGlob(flags.Arg(0)) || *format == "jsonl" {
+		if *checkpointPath != "" {
+			printUsage(writer)
+			return AppResult{ExitCode: 1, Error: fmt.Errorf("checkpoints need a single input file")}
+		}
+		return runBatch(flags.Args(), settings, *jobs, writer)
+	}
+
+	filename := flags.Arg(0)
 
-	// Parse tetrominoes from file
-	tetrominoes, err := parser.ReadFile(filename)
+	// Parse tetrominoes, and any board settings, from file
+	puzzle, err := settings.readPuzzle(filename)
 	if err != nil {
 		fmt.Fprintln(writer, "ERROR")
 		return AppResult{ExitCode: 1, Error: err}
 	}
 
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	if *checkpointPath != "" {
+		if err := setupCheckpoint(&puzzle.Options, filename, *checkpointPath, *checkpointEvery, *resume, cancel); err != nil {
+			fmt.Fprintln(writer, "ERROR")
+			return AppResult{ExitCode: 1, Error: err}
+		}
+	}
+
 	// Solve the tetris puzzle
-	result, err := solver.SolveOptimal(tetrominoes)
+	result, err := settings.solve(ctx, puzzle)
+	if cause := context.Cause(ctx); err != nil && cause != nil {
+		err = cause
+	}
 	if err != nil {
 		fmt.Fprintln(writer, "ERROR")
 		return AppResult{ExitCode: 1, Error: err}
@@ -45,7 +166,60 @@ func RunApp(args []string, writer io.Writer) AppResult {
 	}
 
 	// Print the solution
-	output := result.Grid.String()
+	output, err := settings.formatSolution(puzzle.Pieces, result)
+	if err != nil {
+		fmt.Fprintln(writer, "ERROR")
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
 	fmt.Fprint(writer, output)
 	return AppResult{Output: output, ExitCode: 0}
 }
+
+// solveSettings are the command-line settings applied to every input
+type solveSettings struct {
+	inputFormat parser.Format
+	strategy    string
+	order       string
+	timeLimit   time.Duration
+	format      string
+	showStats   bool
+	cache       *cache.Cache
+}
+
+// readPuzzle parses an input file and applies the search settings
+func (s solveSettings) readPuzzle(filename string) (*parser.Puzzle, error) {
+	puzzle, err := parser.ReadPuzzle(filename, s.inputFormat)
+	if err != nil {
+		return nil, err
+	}
+
+	puzzle.Options.Strategy = s.strategy
+	puzzle.Options.Order = s.order
+	puzzle.Options.TimeLimit = s.timeLimit
+	return puzzle, nil
+}
+
+// solve solves a puzzle, through the cache when one is set
+func (s solveSettings) solve(ctx context.Context, puzzle *parser.Puzzle) (*solver.Result, error) {
+	if s.cache != nil {
+		return s.cache.Solve(ctx, puzzle.Pieces, puzzle.Options)
+	}
+	return solver.SolveOptimalContext(ctx, puzzle.Pieces, puzzle.Options)
+}
+
+// formatSolution renders a successful result as text or JSON
+func (s solveSettings) formatSolution(tetrominoes []*tetromino.Tetromino, result *solver.Result) (string, error) {
+	if s.format == "json" {
+		return formatJSON(tetrominoes, result)
+	}
+
+	output := result.Grid.String()
+	if s.showStats {
+		output += report.FormatStats(report.NewStats(tetrominoes, result))
+		if !result.Optimal {
+			output += "optimal: not proven\n"
+		}
+	}
+	return output, nil
+}
@@ -2,9 +2,18 @@ package main
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
+	"net"
 	"os"
+	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/stkisengese/tetris-optimizer/internal/checkpoint"
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
 )
 
 func TestRunAppInvalidArgs(t *testing.T) {
@@ -74,3 +83,515 @@ func TestRunAppWithValidFile(t *testing.T) {
 		t.Error("Expected some output for successful solve")
 	}
 }
+
+func TestRunAppGenerate(t *testing.T) {
+	var buf bytes.Buffer
+
+	result := RunApp([]string{"program", "generate", "-square", "4", "-seed", "5"}, &buf)
+
+	if result.ExitCode != 0 {
+		t.Fatalf("Expected exit code 0, got %d. Error: %v", result.ExitCode, result.Error)
+	}
+
+	// A 4x4 tiling holds 4 pieces, each drawn as 4 lines, separated by blank lines
+	if lines := strings.Count(buf.String(), "\n"); lines != 19 {
+		t.Errorf("Expected 19 lines of output, got %d", lines)
+	}
+}
+
+func TestRunAppGenerateInvalidShapes(t *testing.T) {
+	var buf bytes.Buffer
+
+	result := RunApp([]string{"program", "generate", "-shapes", "Q=1"}, &buf)
+
+	if result.ExitCode != 1 {
+		t.Errorf("Expected exit code 1, got %d", result.ExitCode)
+	}
+}
+
+func TestRunAppJSONOutput(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test_tetris_*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	tmpFile.WriteString("T\n\nO\n")
+	tmpFile.Close()
+
+	var buf bytes.Buffer
+	result := RunApp([]string{"program", "-format", "json", tmpFile.Name()}, &buf)
+
+	if result.ExitCode != 0 {
+		t.Fatalf("Expected exit code 0, got %d. Output: %s", result.ExitCode, buf.String())
+	}
+
+	var doc struct {
+		Size   int
+		Pieces []struct {
+			ID   string
+			Kind string
+		}
+		Stats struct {
+			Kinds map[string]int
+		}
+	}
+	if err := json.Unmarshal(buf.Bytes(), &doc); err != nil {
+		t.Fatalf("Expected valid JSON, got %v: %s", err, buf.String())
+	}
+
+	if len(doc.Pieces) != 2 || doc.Pieces[0].Kind != "T" || doc.Pieces[1].Kind != "O" {
+		t.Errorf("Expected pieces T and O, got %+v", doc.Pieces)
+	}
+
+	if doc.Stats.Kinds["T"] != 1 || doc.Stats.Kinds["O"] != 1 {
+		t.Errorf("Expected one T and one O in stats, got %v", doc.Stats.Kinds)
+	}
+}
+
+func TestRunAppStats(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test_tetris_*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	tmpFile.WriteString("I\n\nI\n\nO\n\nO\n")
+	tmpFile.Close()
+
+	var buf bytes.Buffer
+	result := RunApp([]string{"program", "-stats", tmpFile.Name()}, &buf)
+
+	if result.ExitCode != 0 {
+		t.Fatalf("Expected exit code 0, got %d. Output: %s", result.ExitCode, buf.String())
+	}
+
+	if !strings.Contains(buf.String(), "kinds: I=2 O=2") {
+		t.Errorf("Expected kind counts in stats, got: %s", buf.String())
+	}
+}
+
+func TestRunAppCompactInput(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test_tetris_*.txt")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	tmpFile.WriteString("O x4\n")
+	tmpFile.Close()
+
+	var buf bytes.Buffer
+	result := RunApp([]string{"program", "-input-format", "compact", tmpFile.Name()}, &buf)
+
+	if result.ExitCode != 0 {
+		t.Fatalf("Expected exit code 0, got %d. Output: %s", result.ExitCode, buf.String())
+	}
+
+	if lines := strings.Count(result.Output, "\n"); lines != 4 {
+		t.Errorf("Expected a 4x4 solution for four O pieces, got:\n%s", result.Output)
+	}
+
+	result = RunApp([]string{"program", "-input-format", "blocks", tmpFile.Name()}, &buf)
+	if result.ExitCode != 1 {
+		t.Errorf("Expected compact input to be rejected as blocks, got exit code %d", result.ExitCode)
+	}
+}
+
+func TestRunAppJSONInput(t *testing.T) {
+	tmpFile, err := os.CreateTemp("", "test_tetris_*.json")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	defer os.Remove(tmpFile.Name())
+
+	tmpFile.WriteString(`{"pieces": [{"kind": "O"}], "board": {"mask": ["#..", "...", "..#"]}}`)
+	tmpFile.Close()
+
+	var buf bytes.Buffer
+	result := RunApp([]string{"program", tmpFile.Name()}, &buf)
+
+	if result.ExitCode != 0 {
+		t.Fatalf("Expected exit code 0, got %d. Output: %s", result.ExitCode, buf.String())
+	}
+
+	expected := "#AA\n.AA\n..#\n"
+	if result.Output != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, result.Output)
+	}
+}
+
+func TestRunAppCacheDir(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "pieces.txt")
+	if err := os.WriteFile(input, []byte("I T L\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	var buf bytes.Buffer
+	first := RunApp([]string{"program", "-cache-dir", filepath.Join(dir, "cache"), input}, &buf)
+	if first.ExitCode != 0 {
+		t.Fatalf("Expected exit code 0, got %d. Output: %s", first.ExitCode, buf.String())
+	}
+
+	second := RunApp([]string{"program", "-cache-dir", filepath.Join(dir, "cache"), input}, &buf)
+	if second.Output != first.Output {
+		t.Errorf("Expected cached run to print the same solution, got:\n%s\nand:\n%s", first.Output, second.Output)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(dir, "cache"))
+	if err != nil || len(entries) != 1 {
+		t.Errorf("Expected one cache entry, got %d (%v)", len(entries), err)
+	}
+}
+
+func TestRunAppStrategy(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "pieces.txt")
+	if err := os.WriteFile(input, []byte("O x4\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if result := RunApp([]string{"program", "-strategy", "backtrack", input}, &buf); result.ExitCode != 0 {
+		t.Errorf("Expected exit code 0, got %d. Output: %s", result.ExitCode, buf.String())
+	}
+
+	buf.Reset()
+	result := RunApp([]string{"program", "-strategy", "guess", input}, &buf)
+	if result.ExitCode != 1 || result.Error == nil {
+		t.Errorf("Expected unknown strategy to fail, got exit code %d", result.ExitCode)
+	}
+}
+
+func TestRunAppHeuristic(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "pieces.txt")
+	if err := os.WriteFile(input, []byte("T x4 L x2\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	var buf bytes.Buffer
+	result := RunApp([]string{"program", "-strategy", "heuristic", "-time-limit", "50ms", "-stats", input}, &buf)
+	if result.ExitCode != 0 {
+		t.Fatalf("Expected exit code 0, got %d. Output: %s", result.ExitCode, buf.String())
+	}
+	if !strings.HasSuffix(result.Output, "optimal: not proven\n") {
+		t.Errorf("Expected the result to be marked unproven, got:\n%s", result.Output)
+	}
+}
+
+func TestRunAppResume(t *testing.T) {
+	dir := t.TempDir()
+	input := filepath.Join(dir, "pieces.txt")
+	data := []byte("T x4 L x2\n")
+	if err := os.WriteFile(input, data, 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	// A checkpoint saying 5x5 is done and 6x6 has just started
+	path := filepath.Join(dir, "search.json")
+	err := checkpoint.Save(path, checkpoint.File{
+		InputHash: checkpoint.HashInput(data),
+		Strategy:  solver.DefaultStrategy,
+		Order:     solver.OrderInput,
+		State:     solver.Checkpoint{Size: 6},
+	})
+	if err != nil {
+		t.Fatalf("Failed to save checkpoint: %v", err)
+	}
+
+	var buf bytes.Buffer
+	result := RunApp([]string{"program", "-checkpoint", path, "-resume", input}, &buf)
+	if result.ExitCode != 0 || strings.Count(result.Output, "\n") != 6 {
+		t.Fatalf("Expected a 6x6 solution, got exit code %d. Output: %s", result.ExitCode, buf.String())
+	}
+
+	// The checkpoint belongs to the original input only
+	if err := os.WriteFile(input, []byte("T x4 L x2 O\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+	buf.Reset()
+	result = RunApp([]string{"program", "-checkpoint", path, "-resume", input}, &buf)
+	if result.ExitCode != 1 || !errors.Is(result.Error, checkpoint.ErrMismatch) {
+		t.Errorf("Expected a checkpoint mismatch, got exit code %d (%v)", result.ExitCode, result.Error)
+	}
+
+	buf.Reset()
+	if result := RunApp([]string{"program", "-resume", input}, &buf); result.ExitCode != 1 {
+		t.Errorf("Expected -resume without -checkpoint to fail, got exit code %d", result.ExitCode)
+	}
+}
+
+func TestRunAppCluster(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "pieces.txt")
+	if err := os.WriteFile(input, []byte("T x4 L x2\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	// Find a free port for the coordinator
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	coordinated := make(chan AppResult, 1)
+	go func() {
+		var buf bytes.Buffer
+		coordinated <- RunApp([]string{"program", "coordinate", "-listen", addr, "-lease-timeout", "300ms", input}, &buf)
+	}()
+
+	// Retry until the coordinator is listening
+	var worked AppResult
+	for attempt := 0; attempt < 50; attempt++ {
+		var buf bytes.Buffer
+		worked = RunApp([]string{"program", "work", "-connect", addr, "-name", "test"}, &buf)
+		if worked.ExitCode == 0 {
+			break
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if worked.ExitCode != 0 {
+		t.Fatalf("Expected worker to finish, got %v", worked.Error)
+	}
+
+	result := <-coordinated
+	if result.ExitCode != 0 || strings.Count(result.Output, "\n") != 6 {
+		t.Errorf("Expected a 6x6 solution, got exit code %d (%v). Output: %s", result.ExitCode, result.Error, result.Output)
+	}
+}
+
+// writeBatch creates input files for batch tests, returning their
+// directory
+func writeBatch(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestRunAppBatch(t *testing.T) {
+	dir := writeBatch(t, map[string]string{"a.txt": "O x4\n", "b.txt": "Q\n", "c.txt": "I I\n"})
+
+	var buf bytes.Buffer
+	result := RunApp([]string{"program", "-jobs", "2", filepath.Join(dir, "*.txt")}, &buf)
+	if result.ExitCode != 1 {
+		t.Errorf("Expected exit code 1 with a failing file, got %d", result.ExitCode)
+	}
+
+	// Results come in input order, followed by the summary
+	a := strings.Index(result.Output, "a.txt:\nAABB\n")
+	b := strings.Index(result.Output, "b.txt:\nERROR\n")
+	c := strings.Index(result.Output, "c.txt:\nAAAA\n")
+	summary := strings.Index(result.Output, "FILE ")
+	if a < 0 || b < a || c < b || summary < c {
+		t.Errorf("Expected results in input order then a summary, got:\n%s", result.Output)
+	}
+	if !strings.HasSuffix(result.Output, "2 of 3 files solved\n") {
+		t.Errorf("Expected a solved count, got:\n%s", result.Output)
+	}
+
+	buf.Reset()
+	result = RunApp([]string{"program", filepath.Join(dir, "a.txt"), filepath.Join(dir, "c.txt")}, &buf)
+	if result.ExitCode != 0 {
+		t.Errorf("Expected exit code 0 when every file solves, got %d. Output: %s", result.ExitCode, result.Output)
+	}
+
+	// A file named like a pattern is read as it is, not globbed
+	literal := writeBatch(t, map[string]string{"set[1].txt": "I I\n", "set1.txt": "Q\n"})
+	buf.Reset()
+	result = RunApp([]string{"program", filepath.Join(literal, "set[1].txt")}, &buf)
+	if result.ExitCode != 0 || result.Output != "AAAA\nBBBB\n....\n....\n" {
+		t.Errorf("Expected the literal file solved alone, got exit code %d (%v). Output: %s", result.ExitCode, result.Error, result.Output)
+	}
+
+	buf.Reset()
+	result = RunApp([]string{"program", "-checkpoint", filepath.Join(dir, "ckpt"), filepath.Join(dir, "*.txt")}, &buf)
+	if result.ExitCode != 1 || result.Error == nil {
+		t.Errorf("Expected checkpoints to be refused in batch mode, got exit code %d", result.ExitCode)
+	}
+}
+
+func TestRunAppBatchJSONLines(t *testing.T) {
+	dir := writeBatch(t, map[string]string{"a.txt": "O x4\n", "b.txt": "Q\n"})
+
+	var buf bytes.Buffer
+	result := RunApp([]string{"program", "-format", "jsonl", filepath.Join(dir, "b.txt"), filepath.Join(dir, "a.txt"), filepath.Join(dir, "none-*.txt")}, &buf)
+	if result.ExitCode != 1 {
+		t.Errorf("Expected exit code 1, got %d", result.ExitCode)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(result.Output, "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected one line per file, got:\n%s", result.Output)
+	}
+
+	want := []struct {
+		file    string
+		success bool
+	}{{"b.txt", false}, {"a.txt", true}, {"none-*.txt", false}}
+	for i, line := range lines {
+		var record struct {
+			File     string          `json:"file"`
+			Success  bool            `json:"success"`
+			Size     int             `json:"size"`
+			Solution json.RawMessage `json:"solution"`
+			Error    string          `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			t.Fatalf("Expected JSON on line %d, got %v: %s", i+1, err, line)
+		}
+		if filepath.Base(record.File) != want[i].file || record.Success != want[i].success {
+			t.Errorf("Line %d: expected %s success=%v, got %s success=%v", i+1, want[i].file, want[i].success, record.File, record.Success)
+		}
+		if record.Success && (record.Size != 4 || record.Solution == nil) {
+			t.Errorf("Line %d: expected a 4x4 solution, got %s", i+1, line)
+		}
+		if !record.Success && record.Error == "" {
+			t.Errorf("Line %d: expected an error message, got %s", i+1, line)
+		}
+	}
+}
+
+func TestRunAppDimacs(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "pieces.txt")
+	if err := os.WriteFile(input, []byte("O O\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write input: %v", err)
+	}
+
+	var buf bytes.Buffer
+	result := RunApp([]string{"program", "dimacs", "-size", "3", input}, &buf)
+	if result.ExitCode != 0 {
+		t.Fatalf("Expected exit code 0, got %d. Output: %s", result.ExitCode, buf.String())
+	}
+
+	if !strings.HasPrefix(result.Output, "c 2 pieces on a 3x3 board\n") || !strings.Contains(result.Output, "\np cnf ") {
+		t.Errorf("Expected DIMACS with comments and header, got:\n%s", result.Output)
+	}
+
+	buf.Reset()
+	if result := RunApp([]string{"program", "dimacs"}, &buf); result.ExitCode != 1 {
+		t.Errorf("Expected exit code 1 without an input file, got %d", result.ExitCode)
+	}
+}
+
+func TestRunAppServeInvalidAddress(t *testing.T) {
+	var buf bytes.Buffer
+
+	result := RunApp([]string{"program", "serve", "-addr", "invalid-address"}, &buf)
+
+	if result.ExitCode != 1 || result.Error == nil {
+		t.Errorf("Expected listen failure, got exit code %d, error %v", result.ExitCode, result.Error)
+	}
+}
+
+func TestRunAppBench(t *testing.T) {
+	dir := t.TempDir()
+	baseline := filepath.Join(dir, "baseline.json")
+
+	var buf bytes.Buffer
+	result := RunApp([]string{"program", "bench", "-counts", "4", "-mixes", "uniform,blocky", "-reps", "1", "-format", "csv", "-save", baseline, "-corpus-dir", dir}, &buf)
+	if result.ExitCode != 0 {
+		t.Fatalf("Expected exit code 0, got %d (%v). Output: %s", result.ExitCode, result.Error, result.Output)
+	}
+	if lines := strings.Split(strings.TrimSpace(result.Output), "\n"); len(lines) != 3 {
+		t.Errorf("Expected a header and two rows, got:\n%s", result.Output)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "blocky-04.txt")); err != nil {
+		t.Errorf("Expected the corpus to be written, got %v", err)
+	}
+
+	// A baseline that needed fewer nodes makes the run a regression
+	data, err := os.ReadFile(baseline)
+	if err != nil {
+		t.Fatalf("Failed to read baseline: %v", err)
+	}
+	data = regexp.MustCompile(`"nodes": \d+`).ReplaceAll(data, []byte(`"nodes": 1`))
+	if err := os.WriteFile(baseline, data, 0o644); err != nil {
+		t.Fatalf("Failed to write baseline: %v", err)
+	}
+
+	buf.Reset()
+	result = RunApp([]string{"program", "bench", "-counts", "4", "-mixes", "uniform", "-reps", "1", "-baseline", baseline}, &buf)
+	if result.ExitCode != 1 || result.Error == nil {
+		t.Errorf("Expected a regression, got exit code %d", result.ExitCode)
+	}
+	if !strings.Contains(result.Output, "### Regressions\n\n- uniform-04 with backtrack: nodes 1 -> ") {
+		t.Errorf("Expected the regression in the report, got:\n%s", result.Output)
+	}
+
+	buf.Reset()
+	result = RunApp([]string{"program", "bench", "-mixes", "lumpy"}, &buf)
+	if result.ExitCode != 1 || result.Error == nil {
+		t.Errorf("Expected an error for an unknown mix, got exit code %d", result.ExitCode)
+	}
+}
+
+func TestRunAppPlayArgs(t *testing.T) {
+	var buf bytes.Buffer
+	if result := RunApp([]string{"program", "play"}, &buf); result.ExitCode != 1 || !strings.Contains(result.Output+buf.String(), "Usage:") {
+		t.Errorf("Expected usage without an input file, got exit code %d", result.ExitCode)
+	}
+
+	buf.Reset()
+	if result := RunApp([]string{"program", "play", "nonexistent.txt"}, &buf); result.ExitCode != 1 || result.Error == nil {
+		t.Errorf("Expected an error for a missing file, got exit code %d", result.ExitCode)
+	}
+
+	path := filepath.Join(t.TempDir(), "puzzle.txt")
+	if err := os.WriteFile(path, []byte("O x4\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write puzzle: %v", err)
+	}
+	buf.Reset()
+	if result := RunApp([]string{"program", "play", "-strategy", "guess", path}, &buf); result.ExitCode != 1 || result.Error == nil {
+		t.Errorf("Expected an error for an unknown strategy, got exit code %d", result.ExitCode)
+	}
+	buf.Reset()
+	if result := RunApp([]string{"program", "play", "-strategy", "heuristic", path}, &buf); result.ExitCode != 1 || result.Error == nil || !strings.Contains(result.Error.Error(), "hints") {
+		t.Errorf("Expected an error for an anytime strategy, got exit code %d (%v)", result.ExitCode, result.Error)
+	}
+}
+
+func TestRunAppDesign(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "puzzle.txt")
+
+	var buf bytes.Buffer
+	result := RunApp([]string{"program", "design", "-n", "4", "-out", path}, &buf)
+	if result.ExitCode != 0 {
+		t.Fatalf("Expected exit code 0, got %d (%v)", result.ExitCode, result.Error)
+	}
+	for _, want := range []string{"Puzzle:\n", "\nSolution (4x4):\n", "\nDifficulty: "} {
+		if !strings.Contains(result.Output, want) {
+			t.Errorf("Expected %q in the output, got:\n%s", want, result.Output)
+		}
+	}
+
+	// The saved puzzle solves to the same size
+	buf.Reset()
+	solved := RunApp([]string{"program", path}, &buf)
+	if solved.ExitCode != 0 || len(strings.Split(strings.TrimSpace(solved.Output), "\n")) != 4 {
+		t.Errorf("Expected the saved puzzle to solve on 4x4, got:\n%s", solved.Output)
+	}
+
+	buf.Reset()
+	result = RunApp([]string{"program", "design", "-square", "4", "-format", "json"}, &buf)
+	var doc struct {
+		Size     int      `json:"size"`
+		Solution []string `json:"solution"`
+		Rating   struct {
+			Score int `json:"score"`
+		} `json:"rating"`
+	}
+	if err := json.Unmarshal([]byte(result.Output), &doc); err != nil || doc.Size != 4 || len(doc.Solution) != 4 || doc.Rating.Score == 0 {
+		t.Errorf("Expected a JSON design, got %+v (%v):\n%s", doc, err, result.Output)
+	}
+
+	buf.Reset()
+	result = RunApp([]string{"program", "design", "-n", "8", "-attempts", "2"}, &buf)
+	if result.ExitCode != 1 || result.Error == nil {
+		t.Errorf("Expected an error when no design is found, got exit code %d", result.ExitCode)
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/stkisengese/tetris-optimizer/internal/report"
+)
+
+// batchRecord is the outcome for one input file, and one line of
+// JSON Lines output
+type batchRecord struct {
+	File     string           `json:"file"`
+	Success  bool             `json:"success"`
+	Size     int              `json:"size,omitempty"`
+	TimeMS   float64          `json:"time_ms"`
+	Solution *report.Solution `json:"solution,omitempty"`
+	Error    string           `json:"error,omitempty"`
+
+	// output is the solution as printed in text mode
+	output  string
+	elapsed time.Duration
+}
+
+// batchInput is one file of a batch after glob expansion
+type batchInput struct {
+	path string
+
+	// unmatched marks a pattern that matched no files, kept so it is
+	// reported as a failure
+	unmatched bool
+}
+
+// isGlob reports whether an argument is a file pattern rather than a
+// plain path. A file whose name contains pattern characters is a path.
+func isGlob(arg string) bool {
+	if !strings.ContainsAny(arg, "*?[") {
+		return false
+	}
+	_, err := os.Stat(arg)
+	return err != nil
+}
+
+// expandInputs resolves globs in the arguments, keeping their order
+func expandInputs(args []string) []batchInput {
+	var inputs []batchInput
+	for _, arg := range args {
+		if !isGlob(arg) {
+			inputs = append(inputs, batchInput{path: arg})
+			continue
+		}
+
+		matches, err := filepath.Glob(arg)
+		if err != nil || len(matches) == 0 {
+			inputs = append(inputs, batchInput{path: arg, unmatched: true})
+			continue
+		}
+		for _, match := range matches {
+			inputs = append(inputs, batchInput{path: match})
+		}
+	}
+	return inputs
+}
+
+// runBatch solves many files with a pool of workers. Results are
+// printed as each file's turn comes, in input order, followed in text
+// mode by a summary table; the exit code is 1 if any file failed.
+func runBatch(args []string, settings solveSettings, jobs int, writer io.Writer) AppResult {
+	files := expandInputs(args)
+
+	records := make([]batchRecord, len(files))
+	ready := make([]chan struct{}, len(files))
+	for i := range ready {
+		ready[i] = make(chan struct{})
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < jobs && w < len(files); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				start := time.Now()
+				records[i] = settings.solveFile(files[i])
+				records[i].elapsed = time.Since(start)
+				records[i].TimeMS = float64(records[i].elapsed.Microseconds()) / 1000
+				close(ready[i])
+			}
+		}()
+	}
+	go func() {
+		for i := range files {
+			next <- i
+		}
+		close(next)
+	}()
+
+	var output strings.Builder
+	out := io.MultiWriter(writer, &output)
+	failed := false
+	for i := range files {
+		<-ready[i]
+		record := &records[i]
+		failed = failed || !record.Success
+
+		if settings.format == "text" {
+			fmt.Fprintf(out, "%s:\n%s\n", record.File, record.output)
+			continue
+		}
+
+		data, err := json.Marshal(record)
+		if err != nil {
+			data, _ = json.Marshal(batchRecord{File: record.File, Error: err.Error()})
+			failed = true
+		}
+		fmt.Fprintf(out, "%s\n", data)
+	}
+	wg.Wait()
+
+	if settings.format == "text" {
+		writeSummary(out, records)
+	}
+
+	result := AppResult{Output: output.String()}
+	if failed {
+		result.ExitCode = 1
+	}
+	return result
+}
+
+// solveFile solves one input file of a batch
+func (s solveSettings) solveFile(input batchInput) batchRecord {
+	record := batchRecord{File: input.path}
+
+	fail := func(err error) batchRecord {
+		record.Error = err.Error()
+		record.output = "ERROR\n"
+		return record
+	}
+
+	if input.unmatched {
+		return fail(fmt.Errorf("no files match %q", input.path))
+	}
+
+	puzzle, err := s.readPuzzle(input.path)
+	if err != nil {
+		return fail(err)
+	}
+
+	result, err := s.solve(context.Background(), puzzle)
+	if err != nil {
+		return fail(err)
+	}
+	if !result.Success {
+		return fail(fmt.Errorf("no solution up to %dx%d", result.Size, result.Size))
+	}
+
+	record.Success = true
+	record.Size = result.Size
+	if s.format == "text" {
+		record.output, err = s.formatSolution(puzzle.Pieces, result)
+		if err != nil {
+			return fail(err)
+		}
+	} else {
+		solution := report.NewSolution(puzzle.Pieces, result)
+		record.Solution = &solution
+	}
+	return record
+}
+
+// writeSummary prints a table of every file's size, time and outcome
+func writeSummary(w io.Writer, records []batchRecord) {
+	solved := 0
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FILE\tSIZE\tTIME\tRESULT")
+	for _, record := range records {
+		size, status := "-", "error: "+record.Error
+		if record.Success {
+			size, status = fmt.Sprintf("%dx%d", record.Size, record.Size), "ok"
+			solved++
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%v\t%s\n", record.File, size, record.elapsed.Round(time.Microsecond), status)
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "%d of %d files solved\n", solved, len(records))
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stkisengese/tetris-optimizer/internal/bench"
+	"github.com/stkisengese/tetris-optimizer/internal/generator"
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+)
+
+// runBench implements the "bench" command, which times strategies over
+// a generated corpus and reports regressions against a baseline
+func runBench(args []string, writer io.Writer) AppResult {
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	flags.SetOutput(writer)
+
+	counts := flags.String("counts", joinInts(bench.DefaultCounts), "comma-separated piece counts in the corpus")
+	mixes := flags.String("mixes", strings.Join(bench.MixNames(), ","), "comma-separated shape mixes: "+strings.Join(bench.MixNames(), ", "))
+	seed := flags.Int64("seed", 1, "random seed of the corpus")
+	strategies := flags.String("strategies", solver.DefaultStrategy, "comma-separated strategies to compare: "+strings.Join(solver.Strategies(), ", "))
+	order := flags.String("order", solver.OrderInput, "piece order: "+strings.Join(solver.Orders, ", "))
+	reps := flags.Int("reps", 3, "solves per case and strategy")
+	timeout := flags.Duration("timeout", 10*time.Second, "time limit for a single solve")
+	format := flags.String("format", "markdown", "report format: markdown, csv or json")
+	baseline := flags.String("baseline", "", "JSON report to compare against")
+	threshold := flags.Float64("threshold", bench.DefaultThreshold, "relative slowdown flagged as a regression")
+	save := flags.String("save", "", "write the report as JSON to this file, for use as a baseline")
+	corpusDir := flags.String("corpus-dir", "", "also write the corpus inputs to this directory")
+
+	if err := flags.Parse(args); err != nil {
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	write, ok := map[string]func(io.Writer, bench.Report) error{
+		"markdown": bench.WriteMarkdown,
+		"csv":      bench.WriteCSV,
+		"json":     bench.WriteJSON,
+	}[*format]
+	if flags.NArg() != 0 || !ok {
+		printUsage(writer)
+		return AppResult{ExitCode: 1}
+	}
+
+	pieceCounts, err := parseInts(*counts)
+	if err != nil {
+		printUsage(writer)
+		return AppResult{ExitCode: 1, Error: err}
+	}
+	names := strings.Split(*strategies, ",")
+	for _, name := range names {
+		if _, err := solver.Lookup(name); err != nil {
+			printUsage(writer)
+			return AppResult{ExitCode: 1, Error: err}
+		}
+	}
+
+	cases, err := bench.Corpus(pieceCounts, strings.Split(*mixes, ","), *seed)
+	if err != nil {
+		fmt.Fprintln(writer, "ERROR")
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	if *corpusDir != "" {
+		if err := writeCorpus(*corpusDir, cases); err != nil {
+			fmt.Fprintln(writer, "ERROR")
+			return AppResult{ExitCode: 1, Error: err}
+		}
+	}
+
+	var report bench.Report
+	report.Measurements, err = bench.Run(context.Background(), cases, bench.Config{
+		Strategies: names,
+		Order:      *order,
+		Reps:       *reps,
+		Timeout:    *timeout,
+	})
+	if err != nil {
+		fmt.Fprintln(writer, "ERROR")
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	if *baseline != "" {
+		base, err := bench.LoadBaseline(*baseline)
+		if err != nil {
+			fmt.Fprintln(writer, "ERROR")
+			return AppResult{ExitCode: 1, Error: err}
+		}
+		report.Regressions = bench.Compare(base, report.Measurements, *threshold)
+	}
+
+	if *save != "" {
+		file, err := os.Create(*save)
+		if err == nil {
+			err = bench.WriteJSON(file, report)
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		if err != nil {
+			fmt.Fprintln(writer, "ERROR")
+			return AppResult{ExitCode: 1, Error: err}
+		}
+	}
+
+	var output strings.Builder
+	if err := write(&output, report); err != nil {
+		fmt.Fprintln(writer, "ERROR")
+		return AppResult{ExitCode: 1, Error: err}
+	}
+	fmt.Fprint(writer, output.String())
+
+	result := AppResult{Output: output.String()}
+	if len(report.Regressions) > 0 {
+		result.ExitCode = 1
+		result.Error = fmt.Errorf("%d regressions against %s", len(report.Regressions), *baseline)
+	}
+	return result
+}
+
+// writeCorpus saves each case as an input file named after it
+func writeCorpus(dir string, cases []bench.Case) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	for _, c := range cases {
+		path := filepath.Join(dir, c.Name+".txt")
+		if err := os.WriteFile(path, []byte(generator.Format(c.Pieces)), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseInts parses a comma-separated list of positive integers
+func parseInts(list string) ([]int, error) {
+	var values []int
+	for _, field := range strings.Split(list, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(field))
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid count %q, expected a positive integer", field)
+		}
+		values = append(values, n)
+	}
+	return values, nil
+}
+
+// joinInts formats integers as a comma-separated list
+func joinInts(values []int) string {
+	fields := make([]string, len(values))
+	for i, n := range values {
+		fields[i] = strconv.Itoa(n)
+	}
+	return strings.Join(fields, ",")
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/stkisengese/tetris-optimizer/internal/checkpoint"
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+)
+
+// setupCheckpoint makes the solve save its state to path at most once
+// per interval, resuming from the saved state first when asked. A
+// failed save stops the solve through cancel.
+func setupCheckpoint(opts *solver.Options, input, path string, every time.Duration, resume bool, cancel context.CancelCauseFunc) error {
+	data, err := os.ReadFile(input)
+	if err != nil {
+		return fmt.Errorf("cannot read input: %v", err)
+	}
+	hash := checkpoint.HashInput(data)
+
+	if resume {
+		file, err := checkpoint.Load(path)
+		if err != nil {
+			return err
+		}
+		if err := file.Validate(hash, opts.Strategy, opts.Order); err != nil {
+			return err
+		}
+		opts.Resume = &file.State
+	}
+
+	last := time.Now()
+	opts.Checkpoint = func(state solver.Checkpoint) {
+		if time.Since(last) < every {
+			return
+		}
+		last = time.Now()
+
+		err := checkpoint.Save(path, checkpoint.File{
+			InputHash: hash,
+			Strategy:  opts.Strategy,
+			Order:     opts.Order,
+			Saved:     last,
+			State:     state,
+		})
+		if err != nil {
+			cancel(err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/stkisengese/tetris-optimizer/internal/cluster"
+	"github.com/stkisengese/tetris-optimizer/internal/parser"
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+)
+
+// runCoordinate implements the "coordinate" command, which splits one
+// puzzle into branches for "work" processes to search and prints the
+// first solution they find
+func runCoordinate(args []string, writer io.Writer) AppResult {
+	flags := flag.NewFlagSet("coordinate", flag.ContinueOnError)
+	flags.SetOutput(writer)
+
+	listen := flags.String("listen", ":7070", "address workers connect to")
+	inputFormat := flags.String("input-format", string(parser.FormatAuto), "input format: auto, blocks, compact or json")
+	strategy := flags.String("strategy", solver.DefaultStrategy, "search strategy workers use: "+strings.Join(solver.Strategies(), ", "))
+	order := flags.String("order", solver.OrderInput, "piece order: "+strings.Join(solver.Orders, ", "))
+	splitDepth := flags.Int("split-depth", cluster.DefaultSplitDepth, "number of pieces fixed in each branch")
+	leaseTimeout := flags.Duration("lease-timeout", cluster.DefaultLeaseTimeout, "time without a heartbeat before a worker's branch is requeued")
+
+	if err := flags.Parse(args); err != nil {
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	if flags.NArg() != 1 {
+		printUsage(writer)
+		return AppResult{ExitCode: 1}
+	}
+
+	inFormat, err := parser.ParseFormat(*inputFormat)
+	if err != nil {
+		printUsage(writer)
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	input, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(writer, "ERROR")
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	coordinator, err := cluster.NewCoordinator(string(input), inFormat, cluster.Config{
+		Strategy:     *strategy,
+		Order:        *order,
+		SplitDepth:   *splitDepth,
+		LeaseTimeout: *leaseTimeout,
+	})
+	if err != nil {
+		fmt.Fprintln(writer, "ERROR")
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	l, err := net.Listen("tcp", *listen)
+	if err != nil {
+		fmt.Fprintln(writer, "ERROR")
+		return AppResult{ExitCode: 1, Error: err}
+	}
+	go coordinator.Serve(l)
+	defer coordinator.Close()
+
+	result, err := coordinator.Wait(context.Background())
+	if err != nil {
+		fmt.Fprintln(writer, "ERROR")
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	// Keep serving for a heartbeat so busy workers hear the search is over
+	time.Sleep(*leaseTimeout / 3)
+
+	if !result.Success {
+		fmt.Fprintln(writer, "ERROR")
+		return AppResult{ExitCode: 1}
+	}
+
+	output := result.Grid.String()
+	fmt.Fprint(writer, output)
+	return AppResult{Output: output, ExitCode: 0}
+}
+
+// runWork implements the "work" command, which searches branches for a
+// coordinator until the search is over
+func runWork(args []string, writer io.Writer) AppResult {
+	flags := flag.NewFlagSet("work", flag.ContinueOnError)
+	flags.SetOutput(writer)
+
+	hostname, _ := os.Hostname()
+	connect := flags.String("connect", "localhost:7070", "address of the coordinator")
+	name := flags.String("name", fmt.Sprintf("%s-%d", hostname, os.Getpid()), "name identifying this worker, unique per coordinator")
+
+	if err := flags.Parse(args); err != nil {
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	if flags.NArg() != 0 {
+		printUsage(writer)
+		return AppResult{ExitCode: 1}
+	}
+
+	if err := cluster.RunWorker(context.Background(), *connect, *name); err != nil {
+		fmt.Fprintln(writer, "ERROR")
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	return AppResult{ExitCode: 0}
+}
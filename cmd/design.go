@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/stkisengese/tetris-optimizer/internal/design"
+	"github.com/stkisengese/tetris-optimizer/internal/generator"
+)
+
+// designDocument is the JSON form of a designed puzzle
+type designDocument struct {
+	Puzzle   string        `json:"puzzle"`
+	Size     int           `json:"size"`
+	Solution []string      `json:"solution"`
+	Rating   design.Rating `json:"rating"`
+	Attempts int           `json:"attempts"`
+}
+
+// runDesign implements the "design" command, which generates a puzzle
+// with a unique solution and prints it with the solution and a rating
+func runDesign(args []string, writer io.Writer) AppResult {
+	flags := flag.NewFlagSet("design", flag.ContinueOnError)
+	flags.SetOutput(writer)
+
+	count := flags.Int("n", 6, "number of random pieces")
+	square := flags.Int("square", 0, "draw sets that tile a KxK square perfectly instead of random pieces")
+	seed := flags.Int64("seed", 1, "random seed for reproducible output")
+	shapes := flags.String("shapes", "", "shape weights, e.g. I=2,T=1,S=1 (default uniform)")
+	attempts := flags.Int("attempts", design.DefaultAttempts, "candidate piece sets to try")
+	format := flags.String("format", "text", "output format: text or json")
+	out := flags.String("out", "", "also write the puzzle, in the input format, to this file")
+
+	if err := flags.Parse(args); err != nil {
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	if flags.NArg() != 0 || (*format != "text" && *format != "json") {
+		printUsage(writer)
+		return AppResult{ExitCode: 1}
+	}
+
+	weights, err := generator.ParseWeights(*shapes)
+	if err != nil {
+		fmt.Fprintln(writer, "ERROR")
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	puzzle, err := design.Design(context.Background(), design.Options{
+		Count:    *count,
+		Square:   *square,
+		Seed:     *seed,
+		Weights:  weights,
+		Attempts: *attempts,
+	})
+	if err != nil {
+		fmt.Fprintln(writer, "ERROR")
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	input := generator.Format(puzzle.Pieces)
+	if *out != "" {
+		if err := os.WriteFile(*out, []byte(input), 0o644); err != nil {
+			fmt.Fprintln(writer, "ERROR")
+			return AppResult{ExitCode: 1, Error: err}
+		}
+	}
+
+	var output string
+	if *format == "json" {
+		data, err := json.MarshalIndent(designDocument{
+			Puzzle:   input,
+			Size:     puzzle.Size,
+			Solution: strings.Split(strings.TrimSuffix(puzzle.Solution.String(), "\n"), "\n"),
+			Rating:   puzzle.Rating,
+			Attempts: puzzle.Attempts,
+		}, "", "  ")
+		if err != nil {
+			fmt.Fprintln(writer, "ERROR")
+			return AppResult{ExitCode: 1, Error: err}
+		}
+		output = string(data) + "\n"
+	} else {
+		rating := puzzle.Rating
+		output = fmt.Sprintf("Puzzle:\n%s\nSolution (%dx%d):\n%s\nDifficulty: %d/10 (%s, %d nodes)\n",
+			input, puzzle.Size, puzzle.Size, puzzle.Solution, rating.Score, rating.Label, rating.Nodes)
+	}
+
+	fmt.Fprint(writer, output)
+	return AppResult{Output: output, ExitCode: 0}
+}
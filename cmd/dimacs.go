@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/stkisengese/tetris-optimizer/internal/parser"
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+)
+
+// runDimacs implements the "dimacs" command, which writes the SAT
+// encoding of a puzzle at one board size in DIMACS CNF format, for
+// cross-checking with external solvers
+func runDimacs(args []string, writer io.Writer) AppResult {
+	flags := flag.NewFlagSet("dimacs", flag.ContinueOnError)
+	flags.SetOutput(writer)
+
+	inputFormat := flags.String("input-format", string(parser.FormatAuto), "input format: auto, blocks, compact or json")
+	size := flags.Int("size", 0, "board size to encode (default the smallest size allowed)")
+
+	if err := flags.Parse(args); err != nil {
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	if flags.NArg() != 1 {
+		printUsage(writer)
+		return AppResult{ExitCode: 1}
+	}
+
+	inFormat, err := parser.ParseFormat(*inputFormat)
+	if err != nil {
+		printUsage(writer)
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	puzzle, err := parser.ReadPuzzle(flags.Arg(0), inFormat)
+	if err != nil {
+		fmt.Fprintln(writer, "ERROR")
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	if *size == 0 {
+		*size, _, err = solver.SizeRange(puzzle.Pieces, puzzle.Options)
+		if err != nil {
+			fmt.Fprintln(writer, "ERROR")
+			return AppResult{ExitCode: 1, Error: err}
+		}
+	}
+
+	enc, err := solver.EncodeSize(puzzle.Pieces, *size, puzzle.Options)
+	if err != nil {
+		fmt.Fprintln(writer, "ERROR")
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	comments := append([]string{fmt.Sprintf("%d pieces on a %dx%d board", len(puzzle.Pieces), *size, *size)}, enc.Comments()...)
+
+	var output strings.Builder
+	if err := enc.CNF.WriteDIMACS(&output, comments...); err != nil {
+		fmt.Fprintln(writer, "ERROR")
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	fmt.Fprint(writer, output.String())
+	return AppResult{Output: output.String(), ExitCode: 0}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/stkisengese/tetris-optimizer/internal/generator"
+)
+
+// runGenerate implements the "generate" command, which writes a random
+// puzzle in the parser's input format
+func runGenerate(args []string, writer io.Writer) AppResult {
+	flags := flag.NewFlagSet("generate", flag.ContinueOnError)
+	flags.SetOutput(writer)
+
+	count := flags.Int("n", 8, "number of random pieces to generate")
+	seed := flags.Int64("seed", 1, "random seed for reproducible output")
+	shapes := flags.String("shapes", "", "shape weights, e.g. I=2,T=1,S=1 (default uniform)")
+	square := flags.Int("square", 0, "tile a KxK square perfectly instead of drawing random pieces")
+
+	if err := flags.Parse(args); err != nil {
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	weights, err := generator.ParseWeights(*shapes)
+	if err != nil {
+		fmt.Fprintln(writer, "ERROR")
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	pieces, err := generator.Generate(generator.Options{
+		Count:   *count,
+		Seed:    *seed,
+		Weights: weights,
+		Square:  *square,
+	})
+	if err != nil {
+		fmt.Fprintln(writer, "ERROR")
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	output := generator.Format(pieces)
+	fmt.Fprint(writer, output)
+	return AppResult{Output: output, ExitCode: 0}
+}
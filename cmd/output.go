@@ -0,0 +1,18 @@
+package main
+
+import (
+	"encoding/json"
+
+	"github.com/stkisengese/tetris-optimizer/internal/report"
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// formatJSON renders a solution as an indented JSON document
+func formatJSON(tetrominoes []*tetromino.Tetromino, result *solver.Result) (string, error) {
+	data, err := json.MarshalIndent(report.NewSolution(tetrominoes, result), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/stkisengese/tetris-optimizer/internal/parser"
+	"github.com/stkisengese/tetris-optimizer/internal/play"
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+)
+
+// runPlay implements the "play" command, which lets the user pack a
+// puzzle by hand in the terminal
+func runPlay(args []string, writer io.Writer) AppResult {
+	flags := flag.NewFlagSet("play", flag.ContinueOnError)
+	flags.SetOutput(writer)
+
+	inputFormat := flags.String("input-format", string(parser.FormatAuto), "input format: auto, blocks, compact or json")
+	size := flags.Int("size", 0, "board size (default the smallest that could hold the pieces)")
+	strategy := flags.String("strategy", solver.DefaultStrategy, "search strategy used for hints: "+strings.Join(solver.Strategies(), ", "))
+
+	if err := flags.Parse(args); err != nil {
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	if flags.NArg() != 1 || *size < 0 {
+		printUsage(writer)
+		return AppResult{ExitCode: 1}
+	}
+
+	inFormat, err := parser.ParseFormat(*inputFormat)
+	if err != nil {
+		printUsage(writer)
+		return AppResult{ExitCode: 1, Error: err}
+	}
+	if _, err := solver.Lookup(*strategy); err != nil {
+		printUsage(writer)
+		return AppResult{ExitCode: 1, Error: err}
+	}
+	// A hint must prove the pieces don't fit, which anytime searches can't
+	if solver.Anytime(*strategy) {
+		printUsage(writer)
+		return AppResult{ExitCode: 1, Error: fmt.Errorf("strategy %q cannot answer hints", *strategy)}
+	}
+
+	puzzle, err := parser.ReadPuzzle(flags.Arg(0), inFormat)
+	if err != nil {
+		fmt.Fprintln(writer, "ERROR")
+		return AppResult{ExitCode: 1, Error: err}
+	}
+	opts := puzzle.Options
+	opts.Strategy = *strategy
+	if *size > 0 {
+		opts.Size = *size
+	}
+
+	boardSize, _, err := solver.SizeRange(puzzle.Pieces, opts)
+	if err != nil {
+		fmt.Fprintln(writer, "ERROR")
+		return AppResult{ExitCode: 1, Error: err}
+	}
+	game, err := play.New(puzzle.Pieces, boardSize, opts)
+	if err != nil {
+		fmt.Fprintln(writer, "ERROR")
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	restore, err := play.MakeRaw(int(os.Stdin.Fd()))
+	if err != nil {
+		fmt.Fprintln(writer, "ERROR")
+		return AppResult{ExitCode: 1, Error: fmt.Errorf("play needs an interactive terminal: %v", err)}
+	}
+	solved, err := play.Run(context.Background(), game, os.Stdin, writer)
+	restore()
+	if err != nil {
+		fmt.Fprintln(writer, "ERROR")
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	if !solved {
+		fmt.Fprintf(writer, "\n%d of %d pieces placed\n", game.Placed(), len(game.Pieces))
+	}
+	return AppResult{Output: game.Board.String()}
+}
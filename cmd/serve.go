@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/stkisengese/tetris-optimizer/internal/cache"
+	"github.com/stkisengese/tetris-optimizer/internal/jobs"
+	"github.com/stkisengese/tetris-optimizer/internal/server"
+)
+
+// runServe implements the "serve" command, which exposes the optimizer
+// as an HTTP JSON API
+func runServe(args []string, writer io.Writer) AppResult {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	flags.SetOutput(writer)
+
+	addr := flags.String("addr", ":8080", "address to listen on")
+	maxBody := flags.Int64("max-body", server.DefaultMaxBodyBytes, "maximum request body size in bytes")
+	maxBoard := flags.Int("max-board", server.DefaultMaxBoardSize, "largest board side a request may search")
+	timeout := flags.Duration("timeout", server.DefaultTimeout, "maximum time for a single solve")
+	maxSolvers := flags.Int("max-solvers", 0, "maximum concurrent solves (default number of CPUs)")
+	progressEvery := flags.Int("progress-every", 0, "search nodes between streamed progress events (default solver setting)")
+	cacheSize := flags.Int("cache-size", 0, "number of solutions kept in memory (0 disables the cache)")
+	jobsDir := flags.String("jobs-dir", "", "directory storing asynchronous jobs (disables /jobs when empty)")
+	workers := flags.Int("workers", 1, "number of asynchronous jobs solved at once")
+	jobTimeout := flags.Duration("job-timeout", 0, "maximum time for a single asynchronous job (0 means no limit)")
+
+	if err := flags.Parse(args); err != nil {
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	var solutions *cache.Cache
+	if *cacheSize > 0 {
+		solutions = cache.New(cache.NewLRU(*cacheSize))
+	}
+
+	var queue *jobs.Queue
+	if *jobsDir != "" {
+		store, err := jobs.NewFileStore(*jobsDir)
+		if err != nil {
+			fmt.Fprintln(writer, "ERROR")
+			return AppResult{ExitCode: 1, Error: err}
+		}
+		queue, err = jobs.NewQueue(store, jobs.Config{Workers: *workers, Timeout: *jobTimeout})
+		if err != nil {
+			fmt.Fprintln(writer, "ERROR")
+			return AppResult{ExitCode: 1, Error: err}
+		}
+		defer queue.Close()
+	}
+
+	srv := &http.Server{
+		Addr: *addr,
+		Handler: server.New(server.Config{
+			MaxBodyBytes:  *maxBody,
+			MaxBoardSize:  *maxBoard,
+			Timeout:       *timeout,
+			MaxSolvers:    *maxSolvers,
+			ProgressEvery: *progressEvery,
+			Cache:         solutions,
+			Jobs:          queue,
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	fmt.Fprintf(writer, "Listening on %s\n", *addr)
+	if err := srv.ListenAndServe(); err != nil {
+		fmt.Fprintln(writer, "ERROR")
+		return AppResult{ExitCode: 1, Error: err}
+	}
+
+	return AppResult{ExitCode: 0}
+}
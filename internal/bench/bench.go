@@ -0,0 +1,181 @@
+// Package bench measures solver strategies on a generated corpus of
+// puzzles and compares the measurements against a saved baseline, so
+// performance claims and regressions can be checked
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/stkisengese/tetris-optimizer/internal/generator"
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// DefaultCounts are the piece counts in the default corpus
+var DefaultCounts = []int{4, 6, 8, 10}
+
+// Mixes are the shape distributions of the corpus, as generator weight
+// specs. "blocky" favours easy I and O pieces and "skewed" the awkward S,
+// Z and T pieces.
+var Mixes = map[string]string{
+	"uniform": "",
+	"blocky":  "I=2,O=2,J=1,L=1,T=1",
+	"skewed":  "S=2,Z=2,T=2,J=1,L=1",
+}
+
+// MixNames returns the names of the shape mixes in sorted order
+func MixNames() []string {
+	names := make([]string, 0, len(Mixes))
+	for name := range Mixes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Case is one puzzle of the corpus
+type Case struct {
+	Name   string
+	Count  int
+	Mix    string
+	Pieces []*tetromino.Tetromino
+}
+
+// Corpus generates one case for every piece count and mix. The same
+// seed always gives the same corpus, whichever counts and mixes are
+// selected.
+func Corpus(counts []int, mixes []string, seed int64) ([]Case, error) {
+	var cases []Case
+	for _, mix := range mixes {
+		spec, ok := Mixes[mix]
+		if !ok {
+			return nil, fmt.Errorf("unknown mix %q, expected one of %v", mix, MixNames())
+		}
+		weights, err := generator.ParseWeights(spec)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, count := range counts {
+			rng := rand.New(rand.NewSource(seed + int64(count)*1000 + mixSeed(mix)))
+			pieces, err := generator.Random(rng, count, weights)
+			if err != nil {
+				return nil, err
+			}
+			cases = append(cases, Case{
+				Name:   fmt.Sprintf("%s-%02d", mix, count),
+				Count:  count,
+				Mix:    mix,
+				Pieces: pieces,
+			})
+		}
+	}
+	return cases, nil
+}
+
+// mixSeed derives a stable seed offset from a mix name
+func mixSeed(mix string) int64 {
+	var h int64
+	for _, c := range mix {
+		h = h*31 + int64(c)
+	}
+	return h
+}
+
+// Config selects what Run measures
+type Config struct {
+	// Strategies are the solver strategies to compare
+	Strategies []string
+
+	// Order is the piece order used by every strategy
+	Order string
+
+	// Reps is how many times each case is solved per strategy. Zero
+	// means once.
+	Reps int
+
+	// Timeout bounds a single solve. A case that times out is recorded
+	// as such and not repeated. Zero means no limit.
+	Timeout time.Duration
+}
+
+// Measurement is the outcome of one strategy on one case
+type Measurement struct {
+	Case     string  `json:"case"`
+	Strategy string  `json:"strategy"`
+	Pieces   int     `json:"pieces"`
+	Size     int     `json:"size"`
+	Success  bool    `json:"success"`
+	TimedOut bool    `json:"timed_out,omitempty"`
+	Nodes    int     `json:"nodes"`
+	Reps     int     `json:"reps"`
+	MedianMS float64 `json:"median_ms"`
+	MinMS    float64 `json:"min_ms"`
+}
+
+// Run solves every case with every strategy, cfg.Reps times each
+func Run(ctx context.Context, cases []Case, cfg Config) ([]Measurement, error) {
+	reps := cfg.Reps
+	if reps <= 0 {
+		reps = 1
+	}
+
+	var measurements []Measurement
+	for _, c := range cases {
+		for _, strategy := range cfg.Strategies {
+			m, err := measure(ctx, c, strategy, cfg.Order, reps, cfg.Timeout)
+			if err != nil {
+				return nil, fmt.Errorf("%s with %s: %v", c.Name, strategy, err)
+			}
+			measurements = append(measurements, m)
+		}
+	}
+	return measurements, nil
+}
+
+// measure times repeated solves of one case
+func measure(ctx context.Context, c Case, strategy, order string, reps int, timeout time.Duration) (Measurement, error) {
+	m := Measurement{Case: c.Name, Strategy: strategy, Pieces: c.Count}
+	opts := solver.Options{Strategy: strategy, Order: order}
+
+	var times []time.Duration
+	for i := 0; i < reps; i++ {
+		solveCtx, cancel := ctx, context.CancelFunc(func() {})
+		if timeout > 0 {
+			solveCtx, cancel = context.WithTimeout(ctx, timeout)
+		}
+
+		start := time.Now()
+		result, err := solver.SolveOptimalContext(solveCtx, c.Pieces, opts)
+		elapsed := time.Since(start)
+		cancel()
+
+		if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+			m.TimedOut = true
+			times = append(times, elapsed)
+			break
+		}
+		if err != nil {
+			return m, err
+		}
+
+		m.Size, m.Success, m.Nodes = result.Size, result.Success, result.Nodes
+		times = append(times, elapsed)
+	}
+
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+	m.Reps = len(times)
+	m.MinMS = milliseconds(times[0])
+	m.MedianMS = milliseconds(times[len(times)/2])
+	return m, nil
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,140 @@
+package bench_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stkisengese/tetris-optimizer/internal/bench"
+	"github.com/stkisengese/tetris-optimizer/internal/generator"
+)
+
+func TestCorpusIsReproducible(t *testing.T) {
+	all, err := bench.Corpus([]int{4, 6}, bench.MixNames(), 7)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(all) != 2*len(bench.Mixes) {
+		t.Fatalf("Expected one case per count and mix, got %d", len(all))
+	}
+
+	// Selecting a subset gives the same cases
+	some, err := bench.Corpus([]int{6}, []string{"skewed"}, 7)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	for _, c := range all {
+		if c.Name != some[0].Name {
+			continue
+		}
+		if generator.Format(c.Pieces) != generator.Format(some[0].Pieces) || len(c.Pieces) != 6 {
+			t.Errorf("Expected %s to be the same in both corpora", c.Name)
+		}
+	}
+
+	if _, err := bench.Corpus([]int{4}, []string{"lumpy"}, 1); err == nil {
+		t.Error("Expected error for an unknown mix, got nil")
+	}
+}
+
+func TestRun(t *testing.T) {
+	cases, err := bench.Corpus([]int{4}, []string{"uniform"}, 1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	measurements, err := bench.Run(context.Background(), cases, bench.Config{
+		Strategies: []string{"backtrack", "cell-first"},
+		Reps:       2,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(measurements) != 2 {
+		t.Fatalf("Expected one measurement per strategy, got %d", len(measurements))
+	}
+	for _, m := range measurements {
+		if !m.Success || m.Reps != 2 || m.Nodes == 0 || m.MinMS > m.MedianMS {
+			t.Errorf("Expected two successful timed solves, got %+v", m)
+		}
+	}
+	if measurements[0].Size != measurements[1].Size {
+		t.Errorf("Expected strategies to agree on the size, got %d and %d", measurements[0].Size, measurements[1].Size)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	baseline := []bench.Measurement{
+		{Case: "a", Strategy: "backtrack", Success: true, Size: 5, Nodes: 100, MedianMS: 10},
+		{Case: "b", Strategy: "backtrack", Success: true, Size: 5, Nodes: 100, MedianMS: 10},
+		{Case: "c", Strategy: "backtrack", Success: true, Size: 5, Nodes: 100, MedianMS: 10},
+		{Case: "d", Strategy: "backtrack", Success: true, Size: 5, Nodes: 100, MedianMS: 0.1},
+		{Case: "e", Strategy: "backtrack", Success: true, Size: 5, Nodes: 100, MedianMS: 10},
+	}
+	current := []bench.Measurement{
+		{Case: "a", Strategy: "backtrack", Success: true, Size: 5, Nodes: 110, MedianMS: 11},
+		{Case: "b", Strategy: "backtrack", Success: true, Size: 5, Nodes: 200, MedianMS: 10},
+		{Case: "c", Strategy: "backtrack", Success: false, TimedOut: true},
+		// Tripled, but by less than a millisecond
+		{Case: "d", Strategy: "backtrack", Success: true, Size: 5, Nodes: 100, MedianMS: 0.3},
+		{Case: "e", Strategy: "backtrack", Success: true, Size: 5, Nodes: 100, MedianMS: 30},
+		{Case: "new", Strategy: "backtrack", Success: false},
+	}
+
+	regressions := bench.Compare(baseline, current, 0.2)
+	var got []string
+	for _, r := range regressions {
+		got = append(got, r.Case+":"+r.Metric)
+	}
+	if want := "b:nodes c:success e:time"; strings.Join(got, " ") != want {
+		t.Errorf("Expected regressions %s, got %v", want, got)
+	}
+}
+
+func TestReportFormats(t *testing.T) {
+	report := bench.Report{
+		Measurements: []bench.Measurement{
+			{Case: "uniform-04", Strategy: "backtrack", Pieces: 4, Success: true, Size: 5, Nodes: 12, Reps: 1, MedianMS: 0.5, MinMS: 0.5},
+			{Case: "uniform-04", Strategy: "sat", Pieces: 4, TimedOut: true, Reps: 1},
+		},
+		Regressions: []bench.Regression{{Case: "uniform-04", Strategy: "sat", Metric: "success", Baseline: 1}},
+	}
+
+	var md bytes.Buffer
+	if err := bench.WriteMarkdown(&md, report); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.Contains(md.String(), "| uniform-04 | 4 | 5x5, 0.500 ms, 12 nodes | timed out |") || !strings.Contains(md.String(), "- uniform-04 with sat: no longer solved") {
+		t.Errorf("Unexpected markdown:\n%s", md.String())
+	}
+
+	var csv bytes.Buffer
+	if err := bench.WriteCSV(&csv, report); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(csv.String()), "\n"); len(lines) != 3 || !strings.HasPrefix(lines[0], "case,strategy,") {
+		t.Errorf("Unexpected CSV:\n%s", csv.String())
+	}
+
+	// A JSON report is a baseline
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	var data bytes.Buffer
+	if err := bench.WriteJSON(&data, report); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	writeFile(t, path, data.Bytes())
+	baseline, err := bench.LoadBaseline(path)
+	if err != nil || len(baseline) != 2 || baseline[0].Nodes != 12 {
+		t.Errorf("Expected the measurements back, got %+v (%v)", baseline, err)
+	}
+}
+
+// writeFile writes a test fixture
+func writeFile(t *testing.T, path string, data []byte) {
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
@@ -0,0 +1,190 @@
+package bench
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DefaultThreshold is the relative slowdown Compare tolerates when no
+// threshold is given
+const DefaultThreshold = 0.2
+
+// minTimeDelta is the smallest slowdown, in milliseconds, that counts as
+// a regression, so timer noise on fast cases is not flagged
+const minTimeDelta = 1.0
+
+// Report is a set of measurements and any regressions against a
+// baseline. Saved as JSON, it serves as the baseline of later runs.
+type Report struct {
+	Measurements []Measurement `json:"measurements"`
+	Regressions  []Regression  `json:"regressions,omitempty"`
+}
+
+// Regression is a measurement that got worse than its baseline. Metric
+// is "success", "size", "nodes" or "time" (median milliseconds).
+type Regression struct {
+	Case     string  `json:"case"`
+	Strategy string  `json:"strategy"`
+	Metric   string  `json:"metric"`
+	Baseline float64 `json:"baseline"`
+	Current  float64 `json:"current"`
+}
+
+// String describes the regression in one line
+func (r Regression) String() string {
+	if r.Metric == "success" {
+		return fmt.Sprintf("%s with %s: no longer solved", r.Case, r.Strategy)
+	}
+	return fmt.Sprintf("%s with %s: %s %g -> %g", r.Case, r.Strategy, r.Metric, r.Baseline, r.Current)
+}
+
+// Compare flags current measurements that are worse than the baseline
+// measurement of the same case and strategy: no longer solved, solved
+// on a larger board, or more than threshold (a fraction) more nodes or
+// median time. Measurements missing from either side are skipped.
+func Compare(baseline, current []Measurement, threshold float64) []Regression {
+	type key struct{ name, strategy string }
+	base := make(map[key]Measurement, len(baseline))
+	for _, m := range baseline {
+		base[key{m.Case, m.Strategy}] = m
+	}
+
+	var regressions []Regression
+	for _, m := range current {
+		b, ok := base[key{m.Case, m.Strategy}]
+		if !ok {
+			continue
+		}
+
+		regression := Regression{Case: m.Case, Strategy: m.Strategy}
+		switch {
+		case b.Success && !m.Success:
+			regression.Metric = "success"
+			regression.Baseline, regression.Current = 1, 0
+		case !m.Success:
+			continue
+		case m.Size > b.Size:
+			regression.Metric = "size"
+			regression.Baseline, regression.Current = float64(b.Size), float64(m.Size)
+		case float64(m.Nodes) > float64(b.Nodes)*(1+threshold):
+			regression.Metric = "nodes"
+			regression.Baseline, regression.Current = float64(b.Nodes), float64(m.Nodes)
+		case m.MedianMS > b.MedianMS*(1+threshold) && m.MedianMS-b.MedianMS > minTimeDelta:
+			regression.Metric = "time"
+			regression.Baseline, regression.Current = b.MedianMS, m.MedianMS
+		default:
+			continue
+		}
+		regressions = append(regressions, regression)
+	}
+	return regressions
+}
+
+// LoadBaseline reads the measurements of a report saved with WriteJSON
+func LoadBaseline(path string) ([]Measurement, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read baseline: %v", err)
+	}
+
+	var report Report
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("cannot decode baseline: %v", err)
+	}
+	return report.Measurements, nil
+}
+
+// WriteJSON writes the report as indented JSON
+func WriteJSON(w io.Writer, r Report) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// WriteCSV writes one row per measurement. Regressions are not included.
+func WriteCSV(w io.Writer, r Report) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"case", "strategy", "pieces", "size", "success", "timed_out", "nodes", "reps", "median_ms", "min_ms"})
+	for _, m := range r.Measurements {
+		cw.Write([]string{
+			m.Case,
+			m.Strategy,
+			strconv.Itoa(m.Pieces),
+			strconv.Itoa(m.Size),
+			strconv.FormatBool(m.Success),
+			strconv.FormatBool(m.TimedOut),
+			strconv.Itoa(m.Nodes),
+			strconv.Itoa(m.Reps),
+			strconv.FormatFloat(m.MedianMS, 'f', 3, 64),
+			strconv.FormatFloat(m.MinMS, 'f', 3, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// WriteMarkdown writes a comparison table with one row per case and one
+// column per strategy, followed by any regressions
+func WriteMarkdown(w io.Writer, r Report) error {
+	var cases, strategies []string
+	cells := make(map[[2]string]Measurement)
+	pieces := make(map[string]int)
+	for _, m := range r.Measurements {
+		if _, ok := pieces[m.Case]; !ok {
+			cases = append(cases, m.Case)
+			pieces[m.Case] = m.Pieces
+		}
+		if !slices.Contains(strategies, m.Strategy) {
+			strategies = append(strategies, m.Strategy)
+		}
+		cells[[2]string{m.Case, m.Strategy}] = m
+	}
+
+	var b strings.Builder
+	b.WriteString("| Case | Pieces |")
+	for _, s := range strategies {
+		fmt.Fprintf(&b, " %s |", s)
+	}
+	b.WriteString("\n|------|--------|")
+	for range strategies {
+		b.WriteString("------|")
+	}
+	b.WriteString("\n")
+
+	for _, c := range cases {
+		fmt.Fprintf(&b, "| %s | %d |", c, pieces[c])
+		for _, s := range strategies {
+			m, ok := cells[[2]string{c, s}]
+			switch {
+			case !ok:
+				b.WriteString(" |")
+			case m.TimedOut:
+				b.WriteString(" timed out |")
+			case !m.Success:
+				fmt.Fprintf(&b, " no solution, %.3f ms |", m.MedianMS)
+			default:
+				fmt.Fprintf(&b, " %dx%d, %.3f ms, %d nodes |", m.Size, m.Size, m.MedianMS, m.Nodes)
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	if len(r.Regressions) > 0 {
+		b.WriteString("\n### Regressions\n\n")
+		for _, regression := range r.Regressions {
+			fmt.Fprintf(&b, "- %s\n", regression)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
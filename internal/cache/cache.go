@@ -0,0 +1,239 @@
+// Package cache remembers solved puzzles so the same set of pieces is
+// never searched twice. Entries are keyed on the multiset of piece
+// shapes, ignoring labels, input order and rotation, so a hit is
+// relabelled with the caller's piece IDs before it is returned.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/stkisengese/tetris-optimizer/internal/grid"
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// Entry is a cached solve outcome. Grid cells are labelled 'A', 'B', ...
+// in key order rather than with any caller's IDs. Nodes counts the
+// search that produced the entry.
+type Entry struct {
+	Size       int                 `json:"size"`
+	Success    bool                `json:"success"`
+	Optimal    bool                `json:"optimal"`
+	Grid       []string            `json:"grid"`
+	Nodes      int                 `json:"nodes"`
+	Infeasible []solver.Infeasible `json:"infeasible,omitempty"`
+}
+
+// Backend stores entries by key
+type Backend interface {
+	Get(key string) (Entry, bool)
+	Put(key string, entry Entry) error
+}
+
+// Cache sits in front of the solver and answers repeated puzzles from a
+// backend
+type Cache struct {
+	backend Backend
+	hits    atomic.Int64
+	misses  atomic.Int64
+}
+
+// New creates a cache over the given backend
+func New(backend Backend) *Cache {
+	return &Cache{backend: backend}
+}
+
+// Solve returns the cached result for the puzzle if there is one, and
+// otherwise solves it with solver.SolveOptimalContext and stores the
+// outcome. Progress is only reported for puzzles that are searched.
+func (c *Cache) Solve(ctx context.Context, pieces []*tetromino.Tetromino, opts solver.Options) (*solver.Result, error) {
+	order := keyOrder(pieces)
+	key := Key(pieces, opts)
+
+	if entry, ok := c.backend.Get(key); ok {
+		result, err := restore(entry, order)
+		if err == nil {
+			c.hits.Add(1)
+			return result, nil
+		}
+		// A corrupt entry is treated as a miss and overwritten below
+	}
+	c.misses.Add(1)
+
+	result, err := solver.SolveOptimalContext(ctx, pieces, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.backend.Put(key, store(result, order)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Stats returns how many solves were answered from the cache and how many
+// had to search
+func (c *Cache) Stats() (hits, misses int64) {
+	return c.hits.Load(), c.misses.Load()
+}
+
+// Key identifies a puzzle independently of piece labels, input order
+// and piece rotation. It combines the sorted piece keys with the solver
+// strategy, piece order and the options that change the outcome,
+// including the time limit of anytime strategies.
+func Key(pieces []*tetromino.Tetromino, opts solver.Options) string {
+	keys := make([]string, len(pieces))
+	for i, t := range pieces {
+		keys[i] = pieceKey(t)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	strategy := opts.Strategy
+	if strategy == "" {
+		strategy = solver.DefaultStrategy
+	}
+	order := opts.Order
+	if order == "" {
+		order = solver.OrderInput
+	}
+	fmt.Fprintf(&b, "strategy=%s order=%s size=%d max=%d", strategy, order, opts.Size, opts.MaxSize)
+	if solver.Anytime(strategy) {
+		// Anytime strategies may find a smaller board given longer
+		fmt.Fprintf(&b, " time=%s", timeLimit(opts))
+	}
+	if opts.Mask != nil {
+		b.WriteString(" mask=")
+		for y, row := range opts.Mask {
+			if y > 0 {
+				b.WriteByte('/')
+			}
+			for _, blocked := range row {
+				if blocked {
+					b.WriteByte('#')
+				} else {
+					b.WriteByte('.')
+				}
+			}
+		}
+	}
+	b.WriteString(" pieces=")
+	b.WriteString(strings.Join(keys, ","))
+
+	return b.String()
+}
+
+// timeLimit returns the time limit an anytime strategy runs with
+func timeLimit(opts solver.Options) time.Duration {
+	if opts.TimeLimit > 0 {
+		return opts.TimeLimit
+	}
+	return solver.DefaultTimeLimit
+}
+
+// pieceKey identifies a piece up to rotation. Pieces whose rotations or
+// position are constrained keep their exact orientation in the key,
+// since the constraints are relative to it.
+func pieceKey(t *tetromino.Tetromino) string {
+	if len(t.Rotations) == 0 && t.Pin == nil {
+		return t.CanonicalKey()
+	}
+
+	key := t.ShapeKey()
+	if len(t.Rotations) > 0 {
+		key += fmt.Sprintf("@%v", t.Rotations)
+	}
+	if t.Pin != nil {
+		key += fmt.Sprintf("=%d,%d,%d", t.Pin.X, t.Pin.Y, t.Pin.Rotation)
+	}
+	return key
+}
+
+// keyOrder returns the pieces sorted by piece key. The i-th piece in this
+// order is labelled with the i-th letter in cached grids.
+func keyOrder(pieces []*tetromino.Tetromino) []*tetromino.Tetromino {
+	order := make([]*tetromino.Tetromino, len(pieces))
+	copy(order, pieces)
+
+	keys := make(map[*tetromino.Tetromino]string, len(pieces))
+	for _, t := range pieces {
+		keys[t] = pieceKey(t)
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return keys[order[i]] < keys[order[j]]
+	})
+
+	return order
+}
+
+// store converts a result to an entry labelled in key order
+func store(result *solver.Result, order []*tetromino.Tetromino) Entry {
+	labels := make(map[rune]rune, len(order))
+	for i, t := range order {
+		labels[t.ID] = rune('A' + i)
+	}
+
+	entry := Entry{
+		Size:       result.Size,
+		Success:    result.Success,
+		Optimal:    result.Optimal,
+		Nodes:      result.Nodes,
+		Infeasible: result.Infeasible,
+	}
+	if result.Grid != nil {
+		entry.Grid = relabel(result.Grid, labels)
+	}
+	return entry
+}
+
+// restore converts an entry back to a result labelled with the IDs of
+// the pieces in order
+func restore(entry Entry, order []*tetromino.Tetromino) (*solver.Result, error) {
+	labels := make(map[rune]rune, len(order))
+	for i, t := range order {
+		labels[rune('A'+i)] = t.ID
+	}
+
+	result := &solver.Result{
+		Size:       entry.Size,
+		Success:    entry.Success,
+		Optimal:    entry.Optimal,
+		Nodes:      entry.Nodes,
+		Infeasible: entry.Infeasible,
+	}
+	if entry.Grid == nil {
+		return result, nil
+	}
+
+	g, err := grid.FromRows(entry.Grid)
+	if err != nil {
+		return nil, err
+	}
+	result.Grid, err = grid.FromRows(relabel(g, labels))
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// relabel renames the piece cells of a grid, leaving empty and blocked
+// cells alone
+func relabel(g *grid.Grid, labels map[rune]rune) []string {
+	rows := make([]string, len(g.Cells))
+	for y, row := range g.Cells {
+		cells := make([]rune, len(row))
+		for x, cell := range row {
+			if label, ok := labels[cell]; ok {
+				cell = label
+			}
+			cells[x] = cell
+		}
+		rows[y] = string(cells)
+	}
+	return rows
+}
@@ -0,0 +1,141 @@
+package cache_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stkisengese/tetris-optimizer/internal/cache"
+	"github.com/stkisengese/tetris-optimizer/internal/parser"
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// pieces parses a compact piece list
+func pieces(t *testing.T, content string) []*tetromino.Tetromino {
+	tetrominoes, err := parser.Parse(strings.NewReader(content), "test.txt", parser.FormatCompact)
+	if err != nil {
+		t.Fatalf("Expected no error parsing %q, got %v", content, err)
+	}
+	return tetrominoes
+}
+
+func TestKeyIgnoresLabelsOrderAndRotation(t *testing.T) {
+	a := cache.Key(pieces(t, "T L I"), solver.Options{})
+	b := cache.Key(pieces(t, "I@90 T@180 L@270"), solver.Options{})
+	if a != b {
+		t.Errorf("Expected equal keys, got %q and %q", a, b)
+	}
+
+	if c := cache.Key(pieces(t, "T L I"), solver.Options{MaxSize: 6}); c == a {
+		t.Error("Expected options to change the key")
+	}
+	if c := cache.Key(pieces(t, "T L I"), solver.Options{Order: solver.OrderDynamic}); c == a {
+		t.Error("Expected the piece order to change the key")
+	}
+	if c := cache.Key(pieces(t, "T L I"), solver.Options{Order: solver.OrderInput}); c != a {
+		t.Error("Expected the default order to share the input order's key")
+	}
+	if c := cache.Key(pieces(t, "T L O"), solver.Options{}); c == a {
+		t.Error("Expected different pieces to change the key")
+	}
+	if c := cache.Key(pieces(t, "T=0,0 L I"), solver.Options{}); c == a {
+		t.Error("Expected a pin to change the key")
+	}
+
+	// The time limit only matters to anytime strategies
+	if c := cache.Key(pieces(t, "T L I"), solver.Options{TimeLimit: time.Minute}); c != a {
+		t.Error("Expected the time limit not to change an exact strategy's key")
+	}
+	short := cache.Key(pieces(t, "T L I"), solver.Options{Strategy: "heuristic", TimeLimit: time.Second})
+	long := cache.Key(pieces(t, "T L I"), solver.Options{Strategy: "heuristic", TimeLimit: time.Minute})
+	if short == long {
+		t.Error("Expected the time limit to change a heuristic key")
+	}
+	if c := cache.Key(pieces(t, "T L I"), solver.Options{Strategy: "heuristic", TimeLimit: solver.DefaultTimeLimit}); c != cache.Key(pieces(t, "T L I"), solver.Options{Strategy: "heuristic"}) {
+		t.Error("Expected a zero time limit to share the default's key")
+	}
+}
+
+func TestSolveRemapsHit(t *testing.T) {
+	c := cache.New(cache.NewLRU(0))
+
+	first, err := c.Solve(context.Background(), pieces(t, "T L I S"), solver.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Same multiset, different order and rotations: labels must follow
+	// this input, not the cached one
+	again := pieces(t, "S@90 I T@180 L@90")
+	result, err := c.Solve(context.Background(), again, solver.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if hits, misses := c.Stats(); hits != 1 || misses != 1 {
+		t.Errorf("Expected 1 hit and 1 miss, got %d and %d", hits, misses)
+	}
+	if !result.Success {
+		t.Fatal("Expected cached success")
+	}
+	if err := solver.Verify(again, result.Grid); err != nil {
+		t.Errorf("Expected remapped grid to solve the new input, got %v\n%s", err, result.Grid)
+	}
+	if result.Nodes != first.Nodes || !reflect.DeepEqual(result.Infeasible, first.Infeasible) {
+		t.Errorf("Expected the hit to keep nodes %d and bounds %v, got %d and %v", first.Nodes, first.Infeasible, result.Nodes, result.Infeasible)
+	}
+}
+
+func TestLRUEvicts(t *testing.T) {
+	lru := cache.NewLRU(2)
+	lru.Put("a", cache.Entry{Size: 1})
+	lru.Put("b", cache.Entry{Size: 2})
+	lru.Get("a")
+	lru.Put("c", cache.Entry{Size: 3})
+
+	if _, ok := lru.Get("b"); ok {
+		t.Error("Expected least recently used entry to be evicted")
+	}
+	if entry, ok := lru.Get("a"); !ok || entry.Size != 1 {
+		t.Errorf("Expected entry a to survive, got %+v %v", entry, ok)
+	}
+	if lru.Len() != 2 {
+		t.Errorf("Expected 2 entries, got %d", lru.Len())
+	}
+}
+
+func TestDiskPersists(t *testing.T) {
+	dir := t.TempDir()
+
+	disk, err := cache.NewDisk(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := cache.New(disk).Solve(context.Background(), pieces(t, "O O"), solver.Options{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	reopened, err := cache.NewDisk(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	c := cache.New(reopened)
+
+	result, err := c.Solve(context.Background(), pieces(t, "O O"), solver.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if hits, _ := c.Stats(); hits != 1 {
+		t.Errorf("Expected a hit from disk, got %d hits", hits)
+	}
+	if result.Size != 4 {
+		t.Errorf("Expected size 4, got %d", result.Size)
+	}
+
+	if _, ok := reopened.Get("missing"); ok {
+		t.Error("Expected miss for unknown key")
+	}
+}
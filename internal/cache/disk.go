@@ -0,0 +1,78 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Disk is a backend keeping one JSON file per entry in a directory, so
+// the cache is shared between runs
+type Disk struct {
+	dir string
+}
+
+// diskEntry is the file format; the full key is kept to detect hash
+// collisions
+type diskEntry struct {
+	Key   string `json:"key"`
+	Entry Entry  `json:"entry"`
+}
+
+// NewDisk creates a backend in dir, creating the directory if needed
+func NewDisk(dir string) (*Disk, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("cannot create cache directory: %v", err)
+	}
+	return &Disk{dir: dir}, nil
+}
+
+// Get reads an entry. Missing or unreadable files are misses.
+func (d *Disk) Get(key string) (Entry, bool) {
+	data, err := os.ReadFile(d.path(key))
+	if err != nil {
+		return Entry{}, false
+	}
+
+	var stored diskEntry
+	if err := json.Unmarshal(data, &stored); err != nil || stored.Key != key {
+		return Entry{}, false
+	}
+	return stored.Entry, true
+}
+
+// Put writes an entry atomically
+func (d *Disk) Put(key string, entry Entry) error {
+	data, err := json.Marshal(diskEntry{Key: key, Entry: entry})
+	if err != nil {
+		return fmt.Errorf("cannot encode cache entry: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(d.dir, "entry.*.tmp")
+	if err != nil {
+		return fmt.Errorf("cannot write cache entry: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cannot write cache entry: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cannot write cache entry: %v", err)
+	}
+
+	if err := os.Rename(tmp.Name(), d.path(key)); err != nil {
+		return fmt.Errorf("cannot write cache entry: %v", err)
+	}
+	return nil
+}
+
+// path returns the file holding the entry for key
+func (d *Disk) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(d.dir, hex.EncodeToString(sum[:])+".json")
+}
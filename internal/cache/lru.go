@@ -0,0 +1,76 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// DefaultLRUSize is the capacity used for a non-positive LRU size
+const DefaultLRUSize = 1024
+
+// LRU is an in-memory backend that evicts the least recently used entry
+// once it holds more than its capacity
+type LRU struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+// lruItem is the value kept in each list element
+type lruItem struct {
+	key   string
+	entry Entry
+}
+
+// NewLRU creates an in-memory backend holding up to capacity entries
+func NewLRU(capacity int) *LRU {
+	if capacity <= 0 {
+		capacity = DefaultLRUSize
+	}
+	return &LRU{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+// Get returns an entry and marks it as recently used
+func (l *LRU) Get(key string) (Entry, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	elem, ok := l.items[key]
+	if !ok {
+		return Entry{}, false
+	}
+	l.order.MoveToFront(elem)
+	return elem.Value.(*lruItem).entry, true
+}
+
+// Put stores an entry, evicting the oldest one if the cache is full
+func (l *LRU) Put(key string, entry Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if elem, ok := l.items[key]; ok {
+		elem.Value.(*lruItem).entry = entry
+		l.order.MoveToFront(elem)
+		return nil
+	}
+
+	l.items[key] = l.order.PushFront(&lruItem{key: key, entry: entry})
+	if l.order.Len() > l.capacity {
+		oldest := l.order.Back()
+		l.order.Remove(oldest)
+		delete(l.items, oldest.Value.(*lruItem).key)
+	}
+	return nil
+}
+
+// Len returns the number of cached entries
+func (l *LRU) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
@@ -0,0 +1,105 @@
+// Package checkpoint stores the state of a long backtrack search in a
+// file, so an interrupted solve can be resumed
+package checkpoint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+)
+
+// Version is the checkpoint format written by Save. Load rejects any
+// other version.
+const Version = 1
+
+// ErrMismatch is returned by Validate when a checkpoint was taken for a
+// different input or different search options
+var ErrMismatch = errors.New("checkpoint does not match this solve")
+
+// File is the checkpoint document
+type File struct {
+	Version int `json:"version"`
+
+	// InputHash is the HashInput of the input file that was solved
+	InputHash string `json:"input_hash"`
+
+	// Strategy and Order are the search options in use, which the stack
+	// only makes sense with
+	Strategy string `json:"strategy"`
+	Order    string `json:"order"`
+
+	Saved time.Time         `json:"saved"`
+	State solver.Checkpoint `json:"state"`
+}
+
+// HashInput fingerprints the contents of an input file
+func HashInput(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Save writes a checkpoint atomically, replacing any previous one, so an
+// interruption mid-write leaves the last checkpoint intact
+func Save(path string, file File) error {
+	file.Version = Version
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot encode checkpoint: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("cannot save checkpoint: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cannot save checkpoint: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cannot save checkpoint: %v", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("cannot save checkpoint: %v", err)
+	}
+	return nil
+}
+
+// Load reads a checkpoint written by Save
+func Load(path string) (*File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read checkpoint: %v", err)
+	}
+
+	var file File
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("cannot decode checkpoint: %v", err)
+	}
+	if file.Version != Version {
+		return nil, fmt.Errorf("unsupported checkpoint version %d, expected %d", file.Version, Version)
+	}
+
+	return &file, nil
+}
+
+// Validate checks that the checkpoint belongs to a solve of the input
+// with the given hash and search options
+func (f *File) Validate(inputHash, strategy, order string) error {
+	if f.InputHash != inputHash {
+		return fmt.Errorf("%w: it was taken for a different input file", ErrMismatch)
+	}
+	if f.Strategy != strategy || f.Order != order {
+		return fmt.Errorf("%w: it was taken with strategy %q and order %q", ErrMismatch, f.Strategy, f.Order)
+	}
+	return nil
+}
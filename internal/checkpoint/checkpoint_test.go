@@ -0,0 +1,51 @@
+package checkpoint_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stkisengese/tetris-optimizer/internal/checkpoint"
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+)
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search.json")
+	state := solver.Checkpoint{Size: 5, Nodes: 1200, Stack: []solver.Choice{{Piece: "A", Orientation: 1, X: 2, Y: 0}}}
+
+	hash := checkpoint.HashInput([]byte("T x4 L x2\n"))
+	if err := checkpoint.Save(path, checkpoint.File{InputHash: hash, Strategy: "backtrack", State: state}); err != nil {
+		t.Fatalf("Expected no error saving, got %v", err)
+	}
+
+	file, err := checkpoint.Load(path)
+	if err != nil {
+		t.Fatalf("Expected no error loading, got %v", err)
+	}
+	if file.Version != checkpoint.Version || !reflect.DeepEqual(file.State, state) {
+		t.Errorf("Expected version %d with state %+v, got %+v", checkpoint.Version, state, file)
+	}
+
+	if err := file.Validate(hash, "backtrack", ""); err != nil {
+		t.Errorf("Expected checkpoint to match, got %v", err)
+	}
+	if err := file.Validate(checkpoint.HashInput([]byte("O\n")), "backtrack", ""); !errors.Is(err, checkpoint.ErrMismatch) {
+		t.Errorf("Expected ErrMismatch for another input, got %v", err)
+	}
+	if err := file.Validate(hash, "backtrack", "hardest"); !errors.Is(err, checkpoint.ErrMismatch) {
+		t.Errorf("Expected ErrMismatch for another order, got %v", err)
+	}
+}
+
+func TestLoadRejectsOtherVersions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "search.json")
+	if err := os.WriteFile(path, []byte(`{"version": 99, "state": {"size": 4}}`), 0o644); err != nil {
+		t.Fatalf("Failed to write checkpoint: %v", err)
+	}
+
+	if _, err := checkpoint.Load(path); err == nil {
+		t.Error("Expected error for an unknown version, got nil")
+	}
+}
@@ -0,0 +1,121 @@
+package cluster_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/rpc"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stkisengese/tetris-optimizer/internal/cluster"
+	"github.com/stkisengese/tetris-optimizer/internal/parser"
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+)
+
+// input has no 5x5 packing, so every branch at that size must be
+// searched before the 6x6 solution is found
+const input = "T x4 L x2"
+
+// startCoordinator serves a coordinator on a free localhost port
+func startCoordinator(t *testing.T, cfg cluster.Config) (*cluster.Coordinator, string) {
+	c, err := cluster.NewCoordinator(input, parser.FormatCompact, cfg)
+	if err != nil {
+		t.Fatalf("Expected no error creating coordinator, got %v", err)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	go c.Serve(l)
+	t.Cleanup(func() { c.Close() })
+
+	return c, l.Addr().String()
+}
+
+// startWorkers runs n workers, returning a channel with each one's error
+func startWorkers(ctx context.Context, addr string, n int) <-chan error {
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			errs <- cluster.RunWorker(ctx, addr, fmt.Sprintf("worker-%d", i))
+		}()
+	}
+	return errs
+}
+
+// checkSolution waits for the coordinator and checks its result
+func checkSolution(t *testing.T, ctx context.Context, c *cluster.Coordinator) {
+	t.Helper()
+
+	result, err := c.Wait(ctx)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !result.Success || result.Size != 6 || !result.Optimal {
+		t.Fatalf("Expected an optimal 6x6 solution, got success=%v size %d optimal=%v", result.Success, result.Size, result.Optimal)
+	}
+
+	pieces, err := parser.ParseCompact(strings.NewReader(input), "input")
+	if err != nil {
+		t.Fatalf("Failed to parse input: %v", err)
+	}
+	if err := solver.Verify(pieces, result.Grid); err != nil {
+		t.Errorf("Expected a valid grid, got %v\n%s", err, result.Grid)
+	}
+}
+
+func TestClusterSolves(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	c, addr := startCoordinator(t, cluster.Config{LeaseTimeout: 300 * time.Millisecond})
+	errs := startWorkers(ctx, addr, 3)
+	checkSolution(t, ctx, c)
+
+	// The solution ends the search for every worker
+	for i := 0; i < 3; i++ {
+		if err := <-errs; err != nil {
+			t.Errorf("Expected worker to stop cleanly, got %v", err)
+		}
+	}
+}
+
+func TestClusterRequeuesDroppedWork(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	c, addr := startCoordinator(t, cluster.Config{LeaseTimeout: 100 * time.Millisecond})
+
+	// A worker takes a branch and disappears without reporting it
+	client, err := rpc.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("Failed to dial coordinator: %v", err)
+	}
+	var reply cluster.NextReply
+	if err := client.Call("Coordinator.Next", cluster.NextArgs{Worker: "dropped"}, &reply); err != nil || reply.Task == nil {
+		t.Fatalf("Expected a task, got %+v (%v)", reply, err)
+	}
+	client.Close()
+
+	// 5x5 is only finished once the dropped branch is searched again
+	startWorkers(ctx, addr, 2)
+	checkSolution(t, ctx, c)
+}
+
+func TestNewCoordinatorRejectsBadInput(t *testing.T) {
+	if _, err := cluster.NewCoordinator("Q", parser.FormatCompact, cluster.Config{}); err == nil {
+		t.Error("Expected error for invalid input, got nil")
+	}
+	if _, err := cluster.NewCoordinator(input, parser.FormatCompact, cluster.Config{Strategy: "guess"}); err == nil {
+		t.Error("Expected error for an unknown strategy, got nil")
+	}
+	if _, err := cluster.NewCoordinator(input, parser.FormatCompact, cluster.Config{Strategy: "heuristic"}); err == nil {
+		t.Error("Expected error for an anytime strategy, got nil")
+	}
+	if _, err := cluster.NewCoordinator(input, parser.FormatCompact, cluster.Config{Order: "random"}); err == nil {
+		t.Error("Expected error for an unknown order, got nil")
+	}
+}
@@ -0,0 +1,437 @@
+// Package cluster spreads the search for one puzzle across worker
+// processes over TCP. A Coordinator splits each board size into
+// branches with solver.Split and leases them to workers, which pull
+// branches over net/rpc, search them with solver.SolveBranch and report
+// back. The first solution ends the search for everyone; branches whose
+// worker stops sending heartbeats are requeued.
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/rpc"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stkisengese/tetris-optimizer/internal/grid"
+	"github.com/stkisengese/tetris-optimizer/internal/parser"
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// DefaultSplitDepth is how many pieces are fixed in each branch when
+// Config.SplitDepth is zero
+const DefaultSplitDepth = 2
+
+// DefaultLeaseTimeout is how long a worker may go without a heartbeat
+// before its branch is requeued, when Config.LeaseTimeout is zero
+const DefaultLeaseTimeout = 10 * time.Second
+
+// serviceName is the name the coordinator's RPC methods are served under
+const serviceName = "Coordinator"
+
+// Config tunes a Coordinator
+type Config struct {
+	// Strategy and Order are the search options workers use for every
+	// branch
+	Strategy string
+	Order    string
+
+	// SplitDepth is the number of pieces fixed in each branch. Deeper
+	// splits give more, smaller branches.
+	SplitDepth int
+
+	// LeaseTimeout is how long a worker may go without a heartbeat
+	// before its branch is given to another worker
+	LeaseTimeout time.Duration
+}
+
+// Task is a branch leased to a worker, with everything needed to
+// search it
+type Task struct {
+	ID     int
+	Input  string
+	Format parser.Format
+
+	Strategy string
+	Order    string
+	Branch   solver.Branch
+
+	// HeartbeatEvery is how often the worker must send a heartbeat while
+	// it searches
+	HeartbeatEvery time.Duration
+}
+
+// NextArgs asks the coordinator for a branch
+type NextArgs struct {
+	Worker string
+}
+
+// NextReply hands out a Task, or tells the worker to Wait while other
+// workers finish the current size, or that the search is Done
+type NextReply struct {
+	Task *Task
+	Wait bool
+	Done bool
+}
+
+// HeartbeatArgs tells the coordinator a worker is still searching a task
+type HeartbeatArgs struct {
+	Worker string
+	TaskID int
+}
+
+// HeartbeatReply tells the worker to Cancel its task: the search is
+// over, or the task was given to another worker
+type HeartbeatReply struct {
+	Cancel bool
+}
+
+// ReportArgs is the outcome of a searched task. Error is set when the
+// worker could not search it.
+type ReportArgs struct {
+	Worker  string
+	TaskID  int
+	Success bool
+	Grid    []string
+	Nodes   int
+	Error   string
+}
+
+// lease is a task handed to a worker
+type lease struct {
+	task     *Task
+	worker   string
+	deadline time.Time
+}
+
+// Coordinator splits a puzzle into branches and collects the results
+type Coordinator struct {
+	input  string
+	format parser.Format
+	cfg    Config
+	pieces []*tetromino.Tetromino
+	opts   solver.Options
+
+	mu         sync.Mutex
+	size       int
+	maxSize    int
+	nextID     int
+	pending    []*Task
+	leases     map[int]*lease
+	nodes      int
+	infeasible []solver.Infeasible
+	result     *solver.Result
+	err        error
+	done       chan struct{}
+	listener   net.Listener
+	stopped    bool
+}
+
+// NewCoordinator parses the input and splits its smallest board size
+// into branches, ready for workers
+func NewCoordinator(input string, format parser.Format, cfg Config) (*Coordinator, error) {
+	if cfg.SplitDepth <= 0 {
+		cfg.SplitDepth = DefaultSplitDepth
+	}
+	if cfg.LeaseTimeout <= 0 {
+		cfg.LeaseTimeout = DefaultLeaseTimeout
+	}
+
+	puzzle, err := parser.ParsePuzzle(strings.NewReader(input), "input", format)
+	if err != nil {
+		return nil, err
+	}
+	opts := puzzle.Options
+	opts.Strategy = cfg.Strategy
+	opts.Order = cfg.Order
+	// Workers search branches, so reject up front what SolveBranch would
+	if _, err := solver.Lookup(opts.Strategy); err != nil {
+		return nil, err
+	}
+	if solver.Anytime(opts.Strategy) {
+		return nil, fmt.Errorf("strategy %q cannot search a single branch", opts.Strategy)
+	}
+	if err := solver.CheckOrder(opts.Order); err != nil {
+		return nil, err
+	}
+
+	minSize, maxSize, err := solver.SizeRange(puzzle.Pieces, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Coordinator{
+		input:   input,
+		format:  format,
+		cfg:     cfg,
+		pieces:  puzzle.Pieces,
+		opts:    opts,
+		size:    minSize - 1,
+		maxSize: maxSize,
+		leases:  make(map[int]*lease),
+		done:    make(chan struct{}),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(puzzle.Pieces) == 0 {
+		c.finish(&solver.Result{}, nil)
+		return c, nil
+	}
+	if err := c.advance(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Serve accepts worker connections on l until Close is called
+func (c *Coordinator) Serve(l net.Listener) error {
+	server := rpc.NewServer()
+	if err := server.RegisterName(serviceName, &service{c: c}); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.listener = l
+	c.mu.Unlock()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			c.mu.Lock()
+			stopped := c.stopped
+			c.mu.Unlock()
+			if stopped {
+				return nil
+			}
+			return err
+		}
+		go server.ServeConn(conn)
+	}
+}
+
+// Close stops accepting workers. Connected workers are told the search
+// is over on their next call.
+func (c *Coordinator) Close() error {
+	c.mu.Lock()
+	c.finish(nil, fmt.Errorf("coordinator closed"))
+	c.stopped = true
+	l := c.listener
+	c.mu.Unlock()
+
+	if l != nil {
+		return l.Close()
+	}
+	return nil
+}
+
+// Wait blocks until the search is over and returns its result: the
+// first solution found, or an unsuccessful result once every size has
+// been searched
+func (c *Coordinator) Wait(ctx context.Context) (*solver.Result, error) {
+	select {
+	case <-c.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.result, c.err
+}
+
+// advance moves to the next board size with any branches, or finishes
+// unsuccessfully after the largest; the caller must hold the lock
+func (c *Coordinator) advance() error {
+	for c.size < c.maxSize {
+		c.size++
+
+		branches, proof, err := solver.Split(c.pieces, c.size, c.cfg.SplitDepth, c.opts)
+		if err != nil {
+			return err
+		}
+		if proof != nil {
+			c.infeasible = append(c.infeasible, *proof)
+		}
+
+		for _, branch := range branches {
+			c.nextID++
+			c.pending = append(c.pending, &Task{
+				ID:             c.nextID,
+				Input:          c.input,
+				Format:         c.format,
+				Strategy:       c.cfg.Strategy,
+				Order:          c.cfg.Order,
+				Branch:         branch,
+				HeartbeatEvery: c.cfg.LeaseTimeout / 3,
+			})
+		}
+		if len(c.pending) > 0 {
+			return nil
+		}
+	}
+
+	g, err := grid.NewGrid(c.maxSize)
+	if err != nil {
+		return err
+	}
+	c.finish(&solver.Result{Grid: g, Size: c.maxSize, Nodes: c.nodes, Infeasible: c.infeasible}, nil)
+	return nil
+}
+
+// finish ends the search; the caller must hold the lock
+func (c *Coordinator) finish(result *solver.Result, err error) {
+	if c.finished() {
+		return
+	}
+	c.result, c.err = result, err
+	c.pending = nil
+	close(c.done)
+}
+
+// finished reports whether the search is over; the caller must hold the
+// lock
+func (c *Coordinator) finished() bool {
+	return c.result != nil || c.err != nil
+}
+
+// requeueExpired puts branches whose lease ran out back in the queue;
+// the caller must hold the lock
+func (c *Coordinator) requeueExpired(now time.Time) {
+	for id, l := range c.leases {
+		if now.After(l.deadline) {
+			delete(c.leases, id)
+			c.pending = append(c.pending, l.task)
+		}
+	}
+}
+
+// next leases the next branch
+func (c *Coordinator) next(args NextArgs, reply *NextReply) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.finished() {
+		reply.Done = true
+		return
+	}
+
+	now := time.Now()
+	c.requeueExpired(now)
+	if len(c.pending) == 0 {
+		reply.Wait = true
+		return
+	}
+
+	task := c.pending[0]
+	c.pending = c.pending[1:]
+	c.leases[task.ID] = &lease{task: task, worker: args.Worker, deadline: now.Add(c.cfg.LeaseTimeout)}
+	reply.Task = task
+}
+
+// heartbeat extends a worker's lease
+func (c *Coordinator) heartbeat(args HeartbeatArgs, reply *HeartbeatReply) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	l, ok := c.leases[args.TaskID]
+	if c.finished() || !ok || l.worker != args.Worker {
+		reply.Cancel = true
+		return
+	}
+	l.deadline = time.Now().Add(c.cfg.LeaseTimeout)
+}
+
+// report records the outcome of a branch. Reports for branches that
+// were already reported, including requeued ones, are ignored.
+func (c *Coordinator) report(args ReportArgs) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.finished() {
+		return nil
+	}
+	task := c.take(args.TaskID)
+	if task == nil {
+		return nil
+	}
+
+	if args.Error != "" {
+		c.finish(nil, fmt.Errorf("worker %s: %s", args.Worker, args.Error))
+		return nil
+	}
+
+	c.nodes += args.Nodes
+	if args.Success {
+		g, err := grid.FromRows(args.Grid)
+		if err == nil {
+			err = solver.Verify(c.pieces, g)
+		}
+		if err != nil {
+			c.nodes -= args.Nodes
+			c.pending = append(c.pending, task)
+			return fmt.Errorf("invalid solution from worker %s: %v", args.Worker, err)
+		}
+		c.finish(&solver.Result{
+			Grid:       g,
+			Success:    true,
+			Size:       g.Size,
+			Nodes:      c.nodes,
+			Infeasible: c.infeasible,
+			Optimal:    true,
+		}, nil)
+		return nil
+	}
+
+	if len(c.pending) == 0 && len(c.leases) == 0 {
+		if err := c.advance(); err != nil {
+			c.finish(nil, err)
+		}
+	}
+	return nil
+}
+
+// take removes a task from the leased or pending branches, returning
+// nil if it was no longer outstanding; the caller must hold the lock
+func (c *Coordinator) take(id int) *Task {
+	if l, ok := c.leases[id]; ok {
+		delete(c.leases, id)
+		return l.task
+	}
+	for i, task := range c.pending {
+		if task.ID == id {
+			c.pending = append(c.pending[:i], c.pending[i+1:]...)
+			return task
+		}
+	}
+	return nil
+}
+
+// service exposes the coordinator over net/rpc
+type service struct {
+	c *Coordinator
+}
+
+// Next hands out the next branch
+func (s *service) Next(args NextArgs, reply *NextReply) error {
+	s.c.next(args, reply)
+	return nil
+}
+
+// Heartbeat keeps a lease alive
+func (s *service) Heartbeat(args HeartbeatArgs, reply *HeartbeatReply) error {
+	s.c.heartbeat(args, reply)
+	return nil
+}
+
+// Report records the outcome of a branch
+func (s *service) Report(args ReportArgs, reply *bool) error {
+	if err := s.c.report(args); err != nil {
+		return err
+	}
+	*reply = true
+	return nil
+}
@@ -0,0 +1,143 @@
+package cluster
+
+import (
+	"context"
+	"net/rpc"
+	"strings"
+	"time"
+
+	"github.com/stkisengese/tetris-optimizer/internal/parser"
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// pollInterval is how long a worker waits before asking again when the
+// coordinator has no branch free
+const pollInterval = 50 * time.Millisecond
+
+// worker searches branches for a coordinator
+type worker struct {
+	// name identifies the worker to the coordinator; it must be unique
+	// among the coordinator's workers
+	name   string
+	client *rpc.Client
+
+	// input and pieces cache the last parsed puzzle, since every task of
+	// a search carries the same input
+	input  string
+	pieces []*tetromino.Tetromino
+	opts   solver.Options
+}
+
+// RunWorker connects to the coordinator at addr and searches branches
+// until the coordinator reports the search is over, which returns nil,
+// or ctx is done or the connection fails
+func RunWorker(ctx context.Context, addr, name string) error {
+	client, err := rpc.Dial("tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	w := &worker{name: name, client: client}
+	return w.run(ctx)
+}
+
+// run pulls and searches tasks until there are none left
+func (w *worker) run(ctx context.Context) error {
+	for {
+		var reply NextReply
+		if err := w.client.Call(serviceName+".Next", NextArgs{Worker: w.name}, &reply); err != nil {
+			return err
+		}
+
+		switch {
+		case reply.Done:
+			return nil
+		case reply.Wait:
+			select {
+			case <-time.After(pollInterval):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		case reply.Task != nil:
+			if err := w.search(ctx, reply.Task); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// search solves one task, sending heartbeats until it finishes, and
+// reports the outcome. A task the coordinator cancels is dropped
+// without a report.
+func (w *worker) search(ctx context.Context, task *Task) error {
+	taskCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stopped := make(chan struct{})
+	defer close(stopped)
+	go w.heartbeat(task, cancel, stopped)
+
+	report := ReportArgs{Worker: w.name, TaskID: task.ID}
+	result, err := w.solve(taskCtx, task)
+	switch {
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case taskCtx.Err() != nil:
+		return nil
+	case err != nil:
+		report.Error = err.Error()
+	default:
+		report.Success = result.Success
+		report.Nodes = result.Nodes
+		if result.Success {
+			report.Grid = strings.Split(strings.TrimSuffix(result.Grid.String(), "\n"), "\n")
+		}
+	}
+
+	var ok bool
+	return w.client.Call(serviceName+".Report", report, &ok)
+}
+
+// solve parses the task's input, reusing the last puzzle when it is the
+// same, and searches its branch
+func (w *worker) solve(ctx context.Context, task *Task) (*solver.Result, error) {
+	if task.Input != w.input || w.pieces == nil {
+		puzzle, err := parser.ParsePuzzle(strings.NewReader(task.Input), "input", task.Format)
+		if err != nil {
+			return nil, err
+		}
+		w.input, w.pieces, w.opts = task.Input, puzzle.Pieces, puzzle.Options
+	}
+
+	opts := w.opts
+	opts.Strategy = task.Strategy
+	opts.Order = task.Order
+	return solver.SolveBranch(ctx, w.pieces, task.Branch, opts)
+}
+
+// heartbeat keeps the task's lease alive until stopped, cancelling the
+// search when the coordinator asks or cannot be reached
+func (w *worker) heartbeat(task *Task, cancel context.CancelFunc, stopped <-chan struct{}) {
+	every := task.HeartbeatEvery
+	if every <= 0 {
+		every = DefaultLeaseTimeout / 3
+	}
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopped:
+			return
+		case <-ticker.C:
+			var reply HeartbeatReply
+			err := w.client.Call(serviceName+".Heartbeat", HeartbeatArgs{Worker: w.name, TaskID: task.ID}, &reply)
+			if err != nil || reply.Cancel {
+				cancel()
+				return
+			}
+		}
+	}
+}
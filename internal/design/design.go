@@ -0,0 +1,165 @@
+// Package design generates puzzles whose smallest square has exactly
+// one solution, up to symmetry, and rates how hard they are to solve
+package design
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/stkisengese/tetris-optimizer/internal/generator"
+	"github.com/stkisengese/tetris-optimizer/internal/grid"
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// DefaultAttempts is how many candidate piece sets Design tries when
+// Options.Attempts is zero
+const DefaultAttempts = 500
+
+// Options controls how puzzles are designed
+type Options struct {
+	// Count is the number of pieces in random mode
+	Count int
+
+	// Square, when positive, draws candidates that tile a Square x
+	// Square board perfectly instead of random pieces. Such sets are far
+	// more often unique than random ones, whose spare cells can usually
+	// be moved around.
+	Square int
+
+	// Seed seeds the random source so runs are reproducible
+	Seed int64
+
+	// Weights optionally biases the shapes in random mode, as for
+	// generator.Options
+	Weights map[tetromino.Kind]int
+
+	// Attempts caps the candidate piece sets tried
+	Attempts int
+}
+
+// Rating grades how hard a puzzle is for the solver
+type Rating struct {
+	// Nodes is the number of search nodes the backtrack strategy
+	// explores to find the smallest square, including proving every
+	// smaller square impossible
+	Nodes int `json:"nodes"`
+
+	// Score grows by one for every factor of ten in Nodes, on a scale
+	// from 1 to 10
+	Score int `json:"score"`
+
+	// Label names the band Score falls in
+	Label string `json:"label"`
+}
+
+// Puzzle is a designed puzzle with its only solution
+type Puzzle struct {
+	Pieces   []*tetromino.Tetromino
+	Solution *grid.Grid
+	Size     int
+	Rating   Rating
+
+	// Attempts is how many candidates were tried, including this one
+	Attempts int
+}
+
+// Design draws candidate piece sets until one has exactly one solution
+// on its smallest square, and rates it
+func Design(ctx context.Context, opts Options) (*Puzzle, error) {
+	attempts := opts.Attempts
+	if attempts <= 0 {
+		attempts = DefaultAttempts
+	}
+
+	rng := rand.New(rand.NewSource(opts.Seed))
+	for attempt := 1; attempt <= attempts; attempt++ {
+		var pieces []*tetromino.Tetromino
+		var err error
+		if opts.Square > 0 {
+			pieces, err = generator.Tiled(rng, opts.Square)
+		} else {
+			pieces, err = generator.Random(rng, opts.Count, opts.Weights)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// A perfect tiling leaves no room for a smaller square
+		puzzle, err := check(ctx, pieces, opts.Square)
+		if err != nil {
+			return nil, err
+		}
+		if puzzle != nil {
+			puzzle.Attempts = attempt
+			return puzzle, nil
+		}
+	}
+
+	return nil, fmt.Errorf("no puzzle with a unique solution found in %d attempts", attempts)
+}
+
+// check returns the candidate as a puzzle if its smallest square has a
+// unique solution, or nil if it does not. size is the smallest square
+// when already known, or zero.
+func check(ctx context.Context, pieces []*tetromino.Tetromino, size int) (*Puzzle, error) {
+	// Cell-first finds the smallest square much faster than backtrack
+	if size == 0 {
+		result, err := solver.SolveOptimalContext(ctx, pieces, solver.Options{Strategy: "cell-first"})
+		if err != nil || !result.Success {
+			return nil, err
+		}
+		size = result.Size
+	}
+
+	count, err := solver.CountSolutions(ctx, pieces, size, 2, solver.Options{})
+	if err != nil {
+		return nil, err
+	}
+	if count.Solutions != 1 || !count.Complete {
+		return nil, nil
+	}
+
+	rating, err := Rate(ctx, pieces)
+	if err != nil {
+		return nil, err
+	}
+	return &Puzzle{
+		Pieces:   pieces,
+		Solution: count.Grid,
+		Size:     size,
+		Rating:   rating,
+	}, nil
+}
+
+// Rate grades a puzzle by the nodes the backtrack strategy needs to
+// solve it
+func Rate(ctx context.Context, pieces []*tetromino.Tetromino) (Rating, error) {
+	result, err := solver.SolveOptimalContext(ctx, pieces, solver.Options{Strategy: solver.DefaultStrategy})
+	if err != nil {
+		return Rating{}, err
+	}
+	if !result.Success {
+		return Rating{}, fmt.Errorf("the puzzle has no solution")
+	}
+	return NewRating(result.Nodes), nil
+}
+
+// NewRating scores a node count
+func NewRating(nodes int) Rating {
+	score := 1 + int(math.Log10(float64(max(nodes, 1))))
+	score = min(score, 10)
+
+	label := "easy"
+	switch {
+	case score >= 7:
+		label = "expert"
+	case score >= 5:
+		label = "hard"
+	case score >= 3:
+		label = "medium"
+	}
+	return Rating{Nodes: nodes, Score: score, Label: label}
+}
@@ -0,0 +1,77 @@
+package design_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stkisengese/tetris-optimizer/internal/design"
+	"github.com/stkisengese/tetris-optimizer/internal/generator"
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+)
+
+func TestDesign(t *testing.T) {
+	ctx := context.Background()
+
+	for _, opts := range []design.Options{{Count: 4, Seed: 1}, {Count: 6, Seed: 1}, {Square: 4, Seed: 2}} {
+		puzzle, err := design.Design(ctx, opts)
+		if err != nil {
+			t.Fatalf("%+v: expected a puzzle, got %v", opts, err)
+		}
+
+		if err := solver.Verify(puzzle.Pieces, puzzle.Solution); err != nil {
+			t.Errorf("%+v: expected a valid solution, got %v\n%s", opts, err, puzzle.Solution)
+		}
+		result, err := solver.SolveOptimal(puzzle.Pieces)
+		if err != nil || result.Size != puzzle.Size {
+			t.Errorf("%+v: expected %dx%d to be the smallest square, got %d (%v)", opts, puzzle.Size, puzzle.Size, result.Size, err)
+		}
+		count, err := solver.CountSolutions(ctx, puzzle.Pieces, puzzle.Size, 0, solver.Options{})
+		if err != nil || count.Solutions != 1 || !count.Complete {
+			t.Errorf("%+v: expected exactly one solution, got %+v (%v)", opts, count, err)
+		}
+		if puzzle.Rating.Nodes != result.Nodes || puzzle.Rating.Score < 1 || puzzle.Rating.Label == "" {
+			t.Errorf("%+v: expected a rating from %d nodes, got %+v", opts, result.Nodes, puzzle.Rating)
+		}
+
+		// The same seed designs the same puzzle
+		again, err := design.Design(ctx, opts)
+		if err != nil || generator.Format(again.Pieces) != generator.Format(puzzle.Pieces) {
+			t.Errorf("%+v: expected the same puzzle again", opts)
+		}
+	}
+}
+
+func TestDesignGivesUp(t *testing.T) {
+	// Eight pieces leave four spare cells on 6x6, which always allows
+	// several solutions
+	if _, err := design.Design(context.Background(), design.Options{Count: 8, Attempts: 3}); err == nil {
+		t.Error("Expected error, got nil")
+	}
+}
+
+func TestRate(t *testing.T) {
+	pieces, err := generator.Generate(generator.Options{Count: 4, Seed: 1})
+	if err != nil {
+		t.Fatalf("Failed to generate pieces: %v", err)
+	}
+	rating, err := design.Rate(context.Background(), pieces)
+	if err != nil || rating.Nodes == 0 {
+		t.Errorf("Expected a rating, got %+v (%v)", rating, err)
+	}
+
+	testCases := []struct {
+		nodes int
+		score int
+		label string
+	}{
+		{nodes: 1, score: 1, label: "easy"},
+		{nodes: 150, score: 3, label: "medium"},
+		{nodes: 40000, score: 5, label: "hard"},
+		{nodes: 5000000, score: 7, label: "expert"},
+	}
+	for _, tc := range testCases {
+		if got := design.NewRating(tc.nodes); got.Score != tc.score || got.Label != tc.label {
+			t.Errorf("Expected %d nodes to rate %d (%s), got %+v", tc.nodes, tc.score, tc.label, got)
+		}
+	}
+}
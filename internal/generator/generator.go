@@ -0,0 +1,254 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/stkisengese/tetris-optimizer/internal/grid"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// Options controls how a puzzle is generated
+type Options struct {
+	// Count is the number of pieces to generate in random mode
+	Count int
+
+	// Seed seeds the random source so runs are reproducible
+	Seed int64
+
+	// Weights optionally biases the shape distribution in random mode.
+	// Missing kinds are never chosen. A nil or empty map means a uniform
+	// distribution.
+	Weights map[tetromino.Kind]int
+
+	// Square, when positive, switches to tiled mode: a Square x Square
+	// board is tiled perfectly and the pieces are shuffled and rotated,
+	// so a zero-waste solution is known to exist
+	Square int
+}
+
+// Generate builds a random puzzle according to the options
+func Generate(opts Options) ([]*tetromino.Tetromino, error) {
+	rng := rand.New(rand.NewSource(opts.Seed))
+
+	if opts.Square > 0 {
+		return Tiled(rng, opts.Square)
+	}
+	return Random(rng, opts.Count, opts.Weights)
+}
+
+// Random generates n pieces drawn from the given shape weights
+func Random(rng *rand.Rand, n int, weights map[tetromino.Kind]int) ([]*tetromino.Tetromino, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("piece count must be positive, got %d", n)
+	}
+
+	kinds, cumulative, err := distribution(weights)
+	if err != nil {
+		return nil, err
+	}
+	total := cumulative[len(cumulative)-1]
+
+	pieces := make([]*tetromino.Tetromino, n)
+	for i := range pieces {
+		pick := rng.Intn(total)
+		idx := sort.SearchInts(cumulative, pick+1)
+
+		tetro, err := tetromino.NewStandard(rune('A'+i), kinds[idx])
+		if err != nil {
+			return nil, err
+		}
+		rotate(rng, tetro)
+		pieces[i] = tetro
+	}
+
+	return pieces, nil
+}
+
+// Tiled tiles a k x k board perfectly with random tetrominoes, then
+// shuffles and rotates the pieces
+func Tiled(rng *rand.Rand, k int) ([]*tetromino.Tetromino, error) {
+	if k <= 0 || (k*k)%4 != 0 {
+		return nil, fmt.Errorf("square size must be a positive even number, got %d", k)
+	}
+
+	g, err := grid.NewGrid(k)
+	if err != nil {
+		return nil, err
+	}
+
+	orientations := make([]*tetromino.Tetromino, 0, 19)
+	for _, kind := range tetromino.Kinds {
+		base, err := tetromino.NewStandard(0, kind)
+		if err != nil {
+			return nil, err
+		}
+		for _, o := range base.Orientations() {
+			oriented := base.Clone()
+			oriented.Shape = o.Shape
+			orientations = append(orientations, oriented)
+		}
+	}
+
+	var placed []*tetromino.Tetromino
+	if !tile(rng, g, orientations, &placed) {
+		return nil, fmt.Errorf("failed to tile a %dx%d board", k, k)
+	}
+
+	rng.Shuffle(len(placed), func(i, j int) {
+		placed[i], placed[j] = placed[j], placed[i]
+	})
+
+	pieces := make([]*tetromino.Tetromino, len(placed))
+	for i, p := range placed {
+		tetro := p.Clone()
+		tetro.ID = rune('A' + i)
+		rotate(rng, tetro)
+		pieces[i] = tetro
+	}
+
+	return pieces, nil
+}
+
+// tile fills the top-left-most empty cell with a randomly chosen
+// orientation and recurses until the board is full
+func tile(rng *rand.Rand, g *grid.Grid, orientations []*tetromino.Tetromino, placed *[]*tetromino.Tetromino) bool {
+	cx, cy, ok := firstEmpty(g)
+	if !ok {
+		return true
+	}
+
+	for _, i := range rng.Perm(len(orientations)) {
+		o := orientations[i]
+
+		// The first cell in row-major order lands on the empty cell
+		anchor := o.Shape.Cells()[0]
+		p := grid.Placement{Piece: o.ID, Orientation: tetromino.Orientation{Shape: o.Shape}, X: cx - anchor.X, Y: cy - anchor.Y}
+		if err := g.Place(p); err != nil {
+			continue
+		}
+		*placed = append(*placed, o)
+
+		if tile(rng, g, orientations, placed) {
+			return true
+		}
+
+		g.Remove(p)
+		*placed = (*placed)[:len(*placed)-1]
+	}
+
+	return false
+}
+
+// firstEmpty returns the top-left-most empty cell in row-major order
+func firstEmpty(g *grid.Grid) (int, int, bool) {
+	for y := 0; y < g.Size; y++ {
+		for x := 0; x < g.Size; x++ {
+			if g.IsEmpty(x, y) {
+				return x, y, true
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+// rotate turns the tetromino by a random multiple of 90 degrees
+func rotate(rng *rand.Rand, t *tetromino.Tetromino) {
+	for n := rng.Intn(4); n > 0; n-- {
+		t.Rotate90()
+	}
+}
+
+// distribution converts shape weights into a cumulative table
+func distribution(weights map[tetromino.Kind]int) ([]tetromino.Kind, []int, error) {
+	var kinds []tetromino.Kind
+	var cumulative []int
+	total := 0
+
+	for _, kind := range tetromino.Kinds {
+		w := 1
+		if len(weights) > 0 {
+			w = weights[kind]
+		}
+		if w < 0 {
+			return nil, nil, fmt.Errorf("weight for shape %v must not be negative", kind)
+		}
+		if w == 0 {
+			continue
+		}
+		total += w
+		kinds = append(kinds, kind)
+		cumulative = append(cumulative, total)
+	}
+
+	for kind := range weights {
+		if kind == tetromino.KindUnknown {
+			return nil, nil, fmt.Errorf("unknown shape in distribution")
+		}
+	}
+
+	if total == 0 {
+		return nil, nil, fmt.Errorf("shape distribution has no positive weights")
+	}
+
+	return kinds, cumulative, nil
+}
+
+// ParseWeights parses a distribution such as "I=2,T=1,S=1"
+func ParseWeights(spec string) (map[tetromino.Kind]int, error) {
+	weights := make(map[tetromino.Kind]int)
+	if strings.TrimSpace(spec) == "" {
+		return weights, nil
+	}
+
+	for _, field := range strings.Split(spec, ",") {
+		name, value, found := strings.Cut(strings.TrimSpace(field), "=")
+		if !found {
+			return nil, fmt.Errorf("invalid shape weight %q, expected LETTER=WEIGHT", field)
+		}
+
+		kind, ok := tetromino.ParseKind(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown shape %q", name)
+		}
+
+		w, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight in %q: %v", field, err)
+		}
+		weights[kind] = w
+	}
+
+	return weights, nil
+}
+
+// Format renders pieces in the parser's 4x4 block input format
+func Format(pieces []*tetromino.Tetromino) string {
+	var builder strings.Builder
+
+	for i, t := range pieces {
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+
+		var block [4][4]byte
+		for y := range block {
+			for x := range block[y] {
+				block[y][x] = '.'
+			}
+		}
+		for _, p := range t.Shape.Cells() {
+			block[p.Y][p.X] = '#'
+		}
+
+		for _, row := range block {
+			builder.Write(row[:])
+			builder.WriteString("\n")
+		}
+	}
+
+	return builder.String()
+}
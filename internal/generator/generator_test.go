@@ -0,0 +1,122 @@
+package generator_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stkisengese/tetris-optimizer/internal/generator"
+	"github.com/stkisengese/tetris-optimizer/internal/parser"
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+func TestGenerateRandomRoundTrip(t *testing.T) {
+	pieces, err := generator.Generate(generator.Options{Count: 6, Seed: 42})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(pieces) != 6 {
+		t.Fatalf("Expected 6 pieces, got %d", len(pieces))
+	}
+
+	tmpFile := filepath.Join(t.TempDir(), "puzzle.txt")
+	if err := os.WriteFile(tmpFile, []byte(generator.Format(pieces)), 0o644); err != nil {
+		t.Fatalf("Failed to write puzzle: %v", err)
+	}
+
+	parsed, err := parser.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatalf("Expected generated puzzle to parse, got %v", err)
+	}
+
+	for i := range pieces {
+		if parsed[i].ShapeKey() != pieces[i].ShapeKey() {
+			t.Errorf("Piece %d: expected shape %s, got %s", i, pieces[i].ShapeKey(), parsed[i].ShapeKey())
+		}
+	}
+}
+
+func TestGenerateIsReproducible(t *testing.T) {
+	opts := generator.Options{Count: 10, Seed: 7}
+
+	first, err := generator.Generate(opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	second, err := generator.Generate(opts)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if generator.Format(first) != generator.Format(second) {
+		t.Error("Expected identical output for the same seed")
+	}
+}
+
+func TestGenerateWithWeights(t *testing.T) {
+	weights, err := generator.ParseWeights("O=1")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	pieces, err := generator.Generate(generator.Options{Count: 5, Seed: 3, Weights: weights})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for i, p := range pieces {
+		if p.Kind != tetromino.KindO {
+			t.Errorf("Piece %d: expected an O piece, got %v", i, p.Kind)
+		}
+	}
+}
+
+func TestGenerateTiledIsPerfect(t *testing.T) {
+	pieces, err := generator.Generate(generator.Options{Square: 4, Seed: 11})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(pieces) != 4 {
+		t.Fatalf("Expected 4 pieces for a 4x4 tiling, got %d", len(pieces))
+	}
+
+	result, err := solver.SolveOptimal(pieces)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !result.Success || result.Size != 4 {
+		t.Errorf("Expected a perfect 4x4 solution, got success=%v size=%d", result.Success, result.Size)
+	}
+}
+
+func TestGenerateInvalidOptions(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts generator.Options
+	}{
+		{name: "zero count", opts: generator.Options{Count: 0}},
+		{name: "odd square", opts: generator.Options{Square: 5}},
+		{name: "unknown shape", opts: generator.Options{Count: 2, Weights: map[tetromino.Kind]int{tetromino.KindUnknown: 1}}},
+		{name: "no positive weights", opts: generator.Options{Count: 2, Weights: map[tetromino.Kind]int{tetromino.KindI: 0}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := generator.Generate(tc.opts); err == nil {
+				t.Errorf("Expected error for %s, got nil", tc.name)
+			}
+		})
+	}
+}
+
+func TestParseWeightsInvalid(t *testing.T) {
+	for _, spec := range []string{"I", "IO=2", "T=x", "Q=1"} {
+		if _, err := generator.ParseWeights(spec); err == nil {
+			t.Errorf("Expected error for %q, got nil", spec)
+		}
+	}
+}
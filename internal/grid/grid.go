@@ -7,6 +7,9 @@ import (
 	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
 )
 
+// Blocked marks a cell that is not part of the usable board
+const Blocked = '#'
+
 // Grid represents the solution board
 type Grid struct {
 	// Size is the dimension of the square grid (size x size)
@@ -14,8 +17,32 @@ type Grid struct {
 
 	// Cells contains the grid data, where each cell contains:
 	// - '.' for empty
+	// - '#' for cells blocked out by a board mask
 	// - Letter (A-Z) for tetromino pieces
 	Cells [][]rune
+
+	// placements are the pieces placed with Place, in order
+	placements []Placement
+}
+
+// Placement is a piece put on the board in one orientation, with the
+// top-left corner of the orientation's bounding box at (X, Y). It is a
+// plain value, so the same orientation can be placed any number of
+// times and placements can be compared with ==.
+type Placement struct {
+	Piece       rune
+	Orientation tetromino.Orientation
+	X           int
+	Y           int
+}
+
+// Cells returns the board cells the placement covers
+func (p Placement) Cells() [4]tetromino.Point {
+	cells := p.Orientation.Cells()
+	for i := range cells {
+		cells[i] = cells[i].Add(tetromino.Point{X: p.X, Y: p.Y})
+	}
+	return cells
 }
 
 // NewGrid creates a new empty grid of the specified size
@@ -38,6 +65,48 @@ func NewGrid(size int) (*Grid, error) {
 	}, nil
 }
 
+// Clone returns an independent copy of the grid
+func (g *Grid) Clone() *Grid {
+	cells := make([][]rune, len(g.Cells))
+	for i, row := range g.Cells {
+		cells[i] = make([]rune, len(row))
+		copy(cells[i], row)
+	}
+
+	return &Grid{
+		Size:       g.Size,
+		Cells:      cells,
+		placements: append([]Placement(nil), g.placements...),
+	}
+}
+
+// FromRows builds a grid from its string form, one string per row
+func FromRows(rows []string) (*Grid, error) {
+	g, err := NewGrid(len(rows))
+	if err != nil {
+		return nil, err
+	}
+
+	for y, row := range rows {
+		cells := []rune(row)
+		if len(cells) != g.Size {
+			return nil, fmt.Errorf("grid must be square: row %d has %d cells, expected %d", y, len(cells), g.Size)
+		}
+		g.Cells[y] = cells
+	}
+
+	return g, nil
+}
+
+// Block marks an empty cell as unusable so no piece can cover it
+func (g *Grid) Block(x, y int) error {
+	if !g.IsEmpty(x, y) {
+		return fmt.Errorf("cannot block cell (%d, %d)", x, y)
+	}
+	g.Cells[y][x] = Blocked
+	return nil
+}
+
 // IsEmpty checks if a cell is empty
 func (g *Grid) IsEmpty(x, y int) bool {
 	if !g.IsValidPosition(x, y) {
@@ -51,19 +120,11 @@ func (g *Grid) IsValidPosition(x, y int) bool {
 	return x >= 0 && x < g.Size && y >= 0 && y < g.Size
 }
 
-// CanPlaceTetromino checks if a tetromino can be placed at the given position
-func (g *Grid) CanPlaceTetromino(t *tetromino.Tetromino, x, y int) bool {
-	for _, point := range t.Points {
-		newX := x + point.X
-		newY := y + point.Y
-
-		// Check bounds
-		if !g.IsValidPosition(newX, newY) {
-			return false
-		}
-
-		// Check if cell is empty
-		if !g.IsEmpty(newX, newY) {
+// CanPlace checks if a shape fits on empty cells with the top-left
+// corner of its bounding box at (x, y)
+func (g *Grid) CanPlace(shape tetromino.Shape, x, y int) bool {
+	for _, point := range shape.Cells() {
+		if !g.IsEmpty(x+point.X, y+point.Y) {
 			return false
 		}
 	}
@@ -71,32 +132,54 @@ func (g *Grid) CanPlaceTetromino(t *tetromino.Tetromino, x, y int) bool {
 	return true
 }
 
-// PlaceTetromino places a tetromino on the grid at the given position
-func (g *Grid) PlaceTetromino(t *tetromino.Tetromino, x, y int) error {
-	if !g.CanPlaceTetromino(t, x, y) {
-		return fmt.Errorf("cannot place tetromino %c at position (%d, %d)", t.ID, x, y)
+// Place puts a piece on the board and records the placement
+func (g *Grid) Place(p Placement) error {
+	if !g.CanPlace(p.Orientation.Shape, p.X, p.Y) {
+		return fmt.Errorf("cannot place tetromino %c at position (%d, %d)", p.Piece, p.X, p.Y)
 	}
 
-	for _, point := range t.Points {
-		newX := x + point.X
-		newY := y + point.Y
-		g.Cells[newY][newX] = t.ID
+	for _, point := range p.Cells() {
+		g.Cells[point.Y][point.X] = p.Piece
 	}
-
-	// Update tetromino position
-	t.SetPosition(x, y)
+	g.placements = append(g.placements, p)
 
 	return nil
 }
 
-// RemoveTetromino removes a tetromino from the grid
-func (g *Grid) RemoveTetromino(t *tetromino.Tetromino) {
-	absolutePoints := t.GetAbsolutePoints()
-	for _, point := range absolutePoints {
-		if g.IsValidPosition(point.X, point.Y) {
+// Remove takes a placement made with Place off the board
+func (g *Grid) Remove(p Placement) error {
+	// Searches undo their latest placement first, so look from the end
+	for i := len(g.placements) - 1; i >= 0; i-- {
+		if g.placements[i] != p {
+			continue
+		}
+
+		for _, point := range p.Cells() {
 			g.Cells[point.Y][point.X] = '.'
 		}
+		g.placements = append(g.placements[:i], g.placements[i+1:]...)
+		return nil
+	}
+
+	return fmt.Errorf("tetromino %c is not placed at position (%d, %d)", p.Piece, p.X, p.Y)
+}
+
+// Placements returns the placements on the board, in the order they
+// were made
+func (g *Grid) Placements() []Placement {
+	return append([]Placement(nil), g.placements...)
+}
+
+// PlacementAt returns the placement covering a cell, if any
+func (g *Grid) PlacementAt(x, y int) (Placement, bool) {
+	for _, p := range g.placements {
+		for _, point := range p.Cells() {
+			if point.X == x && point.Y == y {
+				return p, true
+			}
+		}
 	}
+	return Placement{}, false
 }
 
 // String returns a string representation of the grid
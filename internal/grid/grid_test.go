@@ -55,59 +55,55 @@ func TestGridBounds(t *testing.T) {
 	}
 }
 
-func TestGridTetrominoPlacement(t *testing.T) {
-	g, _ := grid.NewGrid(4)
+// square returns a placement of an O piece at (x, y)
+func square(t *testing.T, id rune, x, y int) grid.Placement {
+	t.Helper()
 
-	// Create a simple tetromino
-	tetrominoGrid := []string{
+	tetro, err := tetromino.NewTetromino(id, []string{
 		"##..",
 		"##..",
 		"....",
 		"....",
-	}
-
-	tetro, err := tetromino.NewTetromino('O', tetrominoGrid)
+	})
 	if err != nil {
 		t.Fatalf("Expected no error creating tetromino, got %v", err)
 	}
+	return grid.Placement{Piece: id, Orientation: tetro.Orientation(0), X: x, Y: y}
+}
+
+func TestGridTetrominoPlacement(t *testing.T) {
+	g, _ := grid.NewGrid(4)
+	p := square(t, 'O', 0, 0)
 
 	// Test valid placement
-	if !g.CanPlaceTetromino(tetro, 0, 0) {
+	if !g.CanPlace(p.Orientation.Shape, 0, 0) {
 		t.Error("Should be able to place tetromino at (0,0)")
 	}
 
-	err = g.PlaceTetromino(tetro, 0, 0)
-	if err != nil {
+	if err := g.Place(p); err != nil {
 		t.Errorf("Expected no error placing tetromino, got %v", err)
 	}
 
 	// Test invalid placement (overlapping)
-	if g.CanPlaceTetromino(tetro, 0, 0) {
+	if g.CanPlace(p.Orientation.Shape, 0, 0) {
 		t.Error("Should not be able to place tetromino on occupied cells")
 	}
-}
+	if err := g.Place(p); err == nil {
+		t.Error("Expected error placing tetromino on occupied cells")
+	}
 
-// Add these test functions to your existing grid_test.go file
+	// Test placement off the board
+	if g.CanPlace(p.Orientation.Shape, 3, 3) {
+		t.Error("Should not be able to place tetromino beyond the grid")
+	}
+}
 
 func TestRemoveTetromino(t *testing.T) {
 	g, _ := grid.NewGrid(4)
-
-	// Create a simple tetromino
-	tetrominoGrid := []string{
-		"##..",
-		"##..",
-		"....",
-		"....",
-	}
-
-	tetro, err := tetromino.NewTetromino('O', tetrominoGrid)
-	if err != nil {
-		t.Fatalf("Expected no error creating tetromino, got %v", err)
-	}
+	p := square(t, 'O', 0, 0)
 
 	// Place the tetromino first
-	err = g.PlaceTetromino(tetro, 0, 0)
-	if err != nil {
+	if err := g.Place(p); err != nil {
 		t.Fatalf("Expected no error placing tetromino, got %v", err)
 	}
 
@@ -117,7 +113,9 @@ func TestRemoveTetromino(t *testing.T) {
 	}
 
 	// Remove the tetromino
-	g.RemoveTetromino(tetro)
+	if err := g.Remove(p); err != nil {
+		t.Fatalf("Expected no error removing tetromino, got %v", err)
+	}
 
 	// Verify it's removed (cells should be empty again)
 	if !g.IsEmpty(0, 0) || !g.IsEmpty(0, 1) || !g.IsEmpty(1, 0) || !g.IsEmpty(1, 1) {
@@ -125,12 +123,14 @@ func TestRemoveTetromino(t *testing.T) {
 	}
 
 	// Test removing tetromino at different position
-	err = g.PlaceTetromino(tetro, 2, 2)
-	if err != nil {
+	p.X, p.Y = 2, 2
+	if err := g.Place(p); err != nil {
 		t.Fatalf("Expected no error placing tetromino at (2,2), got %v", err)
 	}
 
-	g.RemoveTetromino(tetro)
+	if err := g.Remove(p); err != nil {
+		t.Fatalf("Expected no error removing tetromino, got %v", err)
+	}
 
 	// Verify removal at new position
 	if !g.IsEmpty(2, 2) || !g.IsEmpty(2, 3) || !g.IsEmpty(3, 2) || !g.IsEmpty(3, 3) {
@@ -138,37 +138,61 @@ func TestRemoveTetromino(t *testing.T) {
 	}
 }
 
-func TestRemoveTetrominoOutOfBounds(t *testing.T) {
+func TestRemoveTetrominoNotPlaced(t *testing.T) {
 	g, _ := grid.NewGrid(3)
+	p := square(t, 'O', 2, 2)
 
-	// Create a tetromino that would extend beyond grid bounds
-	tetrominoGrid := []string{
-		"##..",
-		"##..",
-		"....",
-		"....",
+	// A placement that was never made is an error, and changes nothing
+	if err := g.Remove(p); err == nil {
+		t.Error("Expected error removing a placement that is not on the grid")
 	}
 
-	tetro, err := tetromino.NewTetromino('O', tetrominoGrid)
-	if err != nil {
-		t.Fatalf("Expected no error creating tetromino, got %v", err)
+	if err := g.Place(square(t, 'O', 0, 0)); err != nil {
+		t.Fatalf("Expected no error placing tetromino, got %v", err)
 	}
+	if err := g.Remove(square(t, 'O', 1, 1)); err == nil {
+		t.Error("Expected error removing a piece from the wrong position")
+	}
+	if g.String() != "OO.\nOO.\n...\n" {
+		t.Errorf("Grid should be unchanged, got:\n%s", g.String())
+	}
+}
 
-	// Position tetromino so it would extend beyond bounds
-	// This should not crash the program due to the IsValidPosition check
-	tetro.SetPosition(2, 2) // This would make some points go beyond 3x3 grid
-
-	// This should not panic or cause errors
-	g.RemoveTetromino(tetro)
+func TestPlacements(t *testing.T) {
+	g, _ := grid.NewGrid(4)
 
-	// Grid should remain unchanged since no valid positions were affected
-	for i := 0; i < 3; i++ {
-		for j := 0; j < 3; j++ {
-			if !g.IsEmpty(i, j) {
-				t.Errorf("Grid cell (%d, %d) should remain empty", i, j)
-			}
+	// The same orientation value can be placed twice
+	a, b := square(t, 'A', 0, 0), square(t, 'B', 2, 2)
+	b.Orientation = a.Orientation
+	for _, p := range []grid.Placement{a, b} {
+		if err := g.Place(p); err != nil {
+			t.Fatalf("Expected no error placing %c, got %v", p.Piece, err)
 		}
 	}
+
+	placements := g.Placements()
+	if len(placements) != 2 || placements[0] != a || placements[1] != b {
+		t.Fatalf("Expected placements A and B in order, got %+v", placements)
+	}
+
+	if p, ok := g.PlacementAt(3, 3); !ok || p != b {
+		t.Errorf("Expected B to cover (3, 3), got %+v, %v", p, ok)
+	}
+	if _, ok := g.PlacementAt(3, 0); ok {
+		t.Error("Expected no placement covering an empty cell")
+	}
+
+	// Clones keep their own record of placements
+	clone := g.Clone()
+	if err := clone.Remove(a); err != nil {
+		t.Fatalf("Expected no error removing A from the clone, got %v", err)
+	}
+	if len(g.Placements()) != 2 || len(clone.Placements()) != 1 {
+		t.Errorf("Expected 2 placements on the original and 1 on the clone, got %d and %d", len(g.Placements()), len(clone.Placements()))
+	}
+	if g.String() != "AA..\nAA..\n..BB\n..BB\n" {
+		t.Errorf("Original grid should be unchanged, got:\n%s", g.String())
+	}
 }
 
 func TestGridString(t *testing.T) {
@@ -195,7 +219,7 @@ func TestGridString(t *testing.T) {
 		t.Fatalf("Expected no error creating tetromino, got %v", err)
 	}
 
-	err = g.PlaceTetromino(tetro, 0, 0)
+	err = g.Place(grid.Placement{Piece: tetro.ID, Orientation: tetro.Orientation(0), X: 0, Y: 0})
 	if err != nil {
 		t.Fatalf("Expected no error placing tetromino, got %v", err)
 	}
@@ -237,8 +261,8 @@ func TestGridStringLargerGrid(t *testing.T) {
 	tetro1, _ := tetromino.NewTetromino('A', tetrominoGrid1)
 	tetro2, _ := tetromino.NewTetromino('B', tetrominoGrid2)
 
-	g.PlaceTetromino(tetro1, 0, 0)
-	g.PlaceTetromino(tetro2, 2, 2)
+	g.Place(grid.Placement{Piece: tetro1.ID, Orientation: tetro1.Orientation(0), X: 0, Y: 0})
+	g.Place(grid.Placement{Piece: tetro2.ID, Orientation: tetro2.Orientation(0), X: 2, Y: 2})
 
 	expectedMultiple := "AA...\nAA...\n..BB.\n..BB.\n.....\n"
 	resultMultiple := g.String()
@@ -247,3 +271,62 @@ func TestGridStringLargerGrid(t *testing.T) {
 		t.Errorf("Expected:\n%s\nGot:\n%s", expectedMultiple, resultMultiple)
 	}
 }
+
+func TestBlock(t *testing.T) {
+	g, _ := grid.NewGrid(3)
+
+	if err := g.Block(1, 1); err != nil {
+		t.Fatalf("Expected no error blocking cell, got %v", err)
+	}
+
+	if g.IsEmpty(1, 1) {
+		t.Error("Blocked cell should not be empty")
+	}
+
+	if err := g.Block(1, 1); err == nil {
+		t.Error("Expected error blocking an already blocked cell")
+	}
+
+	if err := g.Block(3, 0); err == nil {
+		t.Error("Expected error blocking a cell outside the grid")
+	}
+
+	expected := "...\n.#.\n...\n"
+	if g.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, g.String())
+	}
+}
+
+func TestFromRows(t *testing.T) {
+	g, err := grid.FromRows([]string{"AA.", "AA.", "..#"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if g.Size != 3 || g.String() != "AA.\nAA.\n..#\n" {
+		t.Errorf("Expected the rows to round-trip, got size %d:\n%s", g.Size, g.String())
+	}
+
+	if _, err := grid.FromRows([]string{"AA", "A"}); err == nil {
+		t.Error("Expected error for ragged rows")
+	}
+
+	if _, err := grid.FromRows(nil); err == nil {
+		t.Error("Expected error for empty grid")
+	}
+}
+
+func TestClone(t *testing.T) {
+	g, _ := grid.NewGrid(2)
+	clone := g.Clone()
+
+	clone.Cells[0][0] = 'A'
+
+	if !g.IsEmpty(0, 0) {
+		t.Error("Expected original grid to be unchanged after modifying the clone")
+	}
+
+	if clone.String() != "A.\n..\n" {
+		t.Errorf("Unexpected clone contents:\n%s", clone.String())
+	}
+}
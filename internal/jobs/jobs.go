@@ -0,0 +1,331 @@
+// Package jobs runs long solves in the background. Jobs are queued,
+// picked up by a pool of workers and persisted to a FileStore, so their
+// status and results survive restarts.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stkisengese/tetris-optimizer/internal/parser"
+	"github.com/stkisengese/tetris-optimizer/internal/report"
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+)
+
+// Status is the lifecycle state of a job
+type Status string
+
+const (
+	// StatusQueued means the job is waiting for a worker
+	StatusQueued Status = "queued"
+
+	// StatusRunning means a worker is solving the job
+	StatusRunning Status = "running"
+
+	// StatusDone means the solve finished and found a solution
+	StatusDone Status = "done"
+
+	// StatusFailed means the input was invalid, no solution exists or
+	// the solve timed out
+	StatusFailed Status = "failed"
+
+	// StatusCancelled means the job was cancelled before it finished
+	StatusCancelled Status = "cancelled"
+)
+
+// Finished reports whether a job in this state will never change again
+func (s Status) Finished() bool {
+	return s == StatusDone || s == StatusFailed || s == StatusCancelled
+}
+
+// Errors returned by Queue methods
+var (
+	ErrNotFound = errors.New("job not found")
+	ErrFinished = errors.New("job already finished")
+	ErrClosed   = errors.New("job queue closed")
+)
+
+// Job is a single submitted puzzle and, once finished, its outcome
+type Job struct {
+	ID       string           `json:"id"`
+	Status   Status           `json:"status"`
+	Input    string           `json:"input"`
+	Format   parser.Format    `json:"format"`
+	Created  time.Time        `json:"created"`
+	Updated  time.Time        `json:"updated"`
+	Result   *report.Solution `json:"result,omitempty"`
+	Error    string           `json:"error,omitempty"`
+	canceled bool
+}
+
+// Config controls the worker pool
+type Config struct {
+	// Workers is the number of jobs solved at once
+	Workers int
+
+	// Timeout bounds how long a single job may run. Zero means no limit.
+	Timeout time.Duration
+
+	// ErrorLog receives the errors workers hit saving jobs, which have
+	// no caller to return them to. Nil uses the standard logger.
+	ErrorLog *log.Logger
+}
+
+// Queue accepts jobs and runs them on a pool of workers
+type Queue struct {
+	cfg   Config
+	store *FileStore
+
+	mu      sync.Mutex
+	cond    *sync.Cond
+	jobs    map[string]*Job
+	pending []string
+	cancels map[string]context.CancelFunc
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+// NewQueue loads the jobs kept in store and starts the workers. Jobs
+// that were queued or running when the previous process stopped are
+// queued again.
+func NewQueue(store *FileStore, cfg Config) (*Queue, error) {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+
+	q := &Queue{
+		cfg:     cfg,
+		store:   store,
+		jobs:    make(map[string]*Job),
+		cancels: make(map[string]context.CancelFunc),
+	}
+	q.cond = sync.NewCond(&q.mu)
+
+	stored, err := store.LoadAll()
+	if err != nil {
+		return nil, err
+	}
+	for _, job := range stored {
+		q.jobs[job.ID] = job
+		if !job.Status.Finished() {
+			job.Status = StatusQueued
+			q.pending = append(q.pending, job.ID)
+		}
+	}
+	sort.Slice(q.pending, func(i, j int) bool {
+		return q.jobs[q.pending[i]].Created.Before(q.jobs[q.pending[j]].Created)
+	})
+
+	for i := 0; i < cfg.Workers; i++ {
+		q.wg.Add(1)
+		go q.work()
+	}
+
+	return q, nil
+}
+
+// Submit validates and queues a puzzle, returning the new job
+func (q *Queue) Submit(input string, format parser.Format) (Job, error) {
+	if _, err := parser.ParsePuzzle(strings.NewReader(input), "input", format); err != nil {
+		return Job{}, err
+	}
+
+	id, err := newID()
+	if err != nil {
+		return Job{}, err
+	}
+
+	now := time.Now().UTC()
+	job := &Job{ID: id, Status: StatusQueued, Input: input, Format: format, Created: now, Updated: now}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return Job{}, ErrClosed
+	}
+	if err := q.store.Save(job); err != nil {
+		return Job{}, err
+	}
+
+	q.jobs[id] = job
+	q.pending = append(q.pending, id)
+	q.cond.Signal()
+
+	return *job, nil
+}
+
+// Get returns a snapshot of a job
+func (q *Queue) Get(id string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	return *job, nil
+}
+
+// Cancel stops a queued or running job
+func (q *Queue) Cancel(id string) (Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	job, ok := q.jobs[id]
+	if !ok {
+		return Job{}, ErrNotFound
+	}
+	if job.Status.Finished() {
+		return *job, ErrFinished
+	}
+
+	if cancel, running := q.cancels[id]; running {
+		// The worker records the cancellation once the solver stops
+		job.canceled = true
+		cancel()
+		return *job, nil
+	}
+
+	q.removePending(id)
+	if err := q.finish(job, StatusCancelled, nil, "cancelled"); err != nil {
+		return Job{}, err
+	}
+	return *job, nil
+}
+
+// Close stops accepting jobs, cancels running ones and waits for the
+// workers to exit. Interrupted jobs stay queued in the store and are
+// picked up again by the next Queue.
+func (q *Queue) Close() {
+	q.mu.Lock()
+	q.closed = true
+	for _, cancel := range q.cancels {
+		cancel()
+	}
+	q.cond.Broadcast()
+	q.mu.Unlock()
+
+	q.wg.Wait()
+}
+
+// work runs jobs until the queue is closed
+func (q *Queue) work() {
+	defer q.wg.Done()
+
+	for {
+		q.mu.Lock()
+		for len(q.pending) == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if q.closed {
+			q.mu.Unlock()
+			return
+		}
+
+		job := q.jobs[q.pending[0]]
+		q.pending = q.pending[1:]
+
+		var ctx context.Context
+		var cancel context.CancelFunc
+		if q.cfg.Timeout > 0 {
+			ctx, cancel = context.WithTimeout(context.Background(), q.cfg.Timeout)
+		} else {
+			ctx, cancel = context.WithCancel(context.Background())
+		}
+		q.cancels[job.ID] = cancel
+		job.Status = StatusRunning
+		job.Updated = time.Now().UTC()
+		q.logError(q.store.Save(job))
+		input, format := job.Input, job.Format
+		q.mu.Unlock()
+
+		solution, err := run(ctx, input, format)
+		cancel()
+
+		q.mu.Lock()
+		delete(q.cancels, job.ID)
+		switch {
+		case err == nil:
+			q.logError(q.finish(job, StatusDone, solution, ""))
+		case job.canceled:
+			q.logError(q.finish(job, StatusCancelled, nil, "cancelled"))
+		case errors.Is(err, context.Canceled) && q.closed:
+			// Shutting down: leave the job queued for the next start
+			job.Status = StatusQueued
+			job.Updated = time.Now().UTC()
+			q.logError(q.store.Save(job))
+		default:
+			q.logError(q.finish(job, StatusFailed, nil, err.Error()))
+		}
+		q.mu.Unlock()
+	}
+}
+
+// finish records the outcome of a job. The caller must hold q.mu.
+func (q *Queue) finish(job *Job, status Status, solution *report.Solution, msg string) error {
+	job.Status = status
+	job.Result = solution
+	job.Error = msg
+	job.Updated = time.Now().UTC()
+	return q.store.Save(job)
+}
+
+// logError reports an error from a worker to the error log
+func (q *Queue) logError(err error) {
+	if err == nil {
+		return
+	}
+	logger := q.cfg.ErrorLog
+	if logger == nil {
+		logger = log.Default()
+	}
+	logger.Printf("jobs: %v", err)
+}
+
+// removePending drops a job from the waiting list. The caller must hold
+// q.mu.
+func (q *Queue) removePending(id string) {
+	for i, pending := range q.pending {
+		if pending == id {
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// run parses and solves a job's input
+func run(ctx context.Context, input string, format parser.Format) (*report.Solution, error) {
+	puzzle, err := parser.ParsePuzzle(strings.NewReader(input), "input", format)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := solver.SolveOptimalContext(ctx, puzzle.Pieces, puzzle.Options)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Success {
+		return nil, errors.New("no solution found")
+	}
+
+	solution := report.NewSolution(puzzle.Pieces, result)
+	return &solution, nil
+}
+
+// newID returns a random job identifier
+func newID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("cannot generate job id: %v", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,161 @@
+package jobs_test
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stkisengese/tetris-optimizer/internal/jobs"
+	"github.com/stkisengese/tetris-optimizer/internal/parser"
+)
+
+// hardInput takes far longer to solve than any test waits
+const hardInput = `{"pieces": [{"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}], "board": {"size": 7}}`
+
+// newQueue opens a queue over a store in dir
+func newQueue(t *testing.T, dir string, cfg jobs.Config) *jobs.Queue {
+	store, err := jobs.NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("Expected no error creating store, got %v", err)
+	}
+	q, err := jobs.NewQueue(store, cfg)
+	if err != nil {
+		t.Fatalf("Expected no error creating queue, got %v", err)
+	}
+	return q
+}
+
+// waitFor polls a job until it reaches status or the test times out
+func waitFor(t *testing.T, q *jobs.Queue, id string, status jobs.Status) jobs.Job {
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		job, err := q.Get(id)
+		if err != nil {
+			t.Fatalf("Expected job %s, got %v", id, err)
+		}
+		if job.Status == status {
+			return job
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected job to become %s, still %s", status, job.Status)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestQueueSolves(t *testing.T) {
+	q := newQueue(t, t.TempDir(), jobs.Config{Workers: 2})
+	defer q.Close()
+
+	job, err := q.Submit("O x4", parser.FormatAuto)
+	if err != nil {
+		t.Fatalf("Expected no error submitting, got %v", err)
+	}
+
+	job = waitFor(t, q, job.ID, jobs.StatusDone)
+	if job.Result == nil || job.Result.Size != 4 {
+		t.Errorf("Expected a 4x4 solution, got %+v", job.Result)
+	}
+}
+
+func TestQueueRejectsInvalidInput(t *testing.T) {
+	q := newQueue(t, t.TempDir(), jobs.Config{})
+	defer q.Close()
+
+	if _, err := q.Submit("Q", parser.FormatAuto); err == nil {
+		t.Error("Expected error for invalid input, got nil")
+	}
+	if _, err := q.Get("missing"); !errors.Is(err, jobs.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestQueueCancel(t *testing.T) {
+	q := newQueue(t, t.TempDir(), jobs.Config{Workers: 1})
+	defer q.Close()
+
+	running, err := q.Submit(hardInput, parser.FormatJSON)
+	if err != nil {
+		t.Fatalf("Expected no error submitting, got %v", err)
+	}
+	waitFor(t, q, running.ID, jobs.StatusRunning)
+
+	// The only worker is busy, so this job stays queued
+	queued, err := q.Submit("O", parser.FormatAuto)
+	if err != nil {
+		t.Fatalf("Expected no error submitting, got %v", err)
+	}
+
+	if job, err := q.Cancel(queued.ID); err != nil || job.Status != jobs.StatusCancelled {
+		t.Errorf("Expected queued job to be cancelled at once, got %s (%v)", job.Status, err)
+	}
+	if _, err := q.Cancel(running.ID); err != nil {
+		t.Fatalf("Expected no error cancelling running job, got %v", err)
+	}
+	waitFor(t, q, running.ID, jobs.StatusCancelled)
+
+	if _, err := q.Cancel(running.ID); !errors.Is(err, jobs.ErrFinished) {
+		t.Errorf("Expected ErrFinished, got %v", err)
+	}
+}
+
+func TestQueueLogsSaveErrors(t *testing.T) {
+	dir := t.TempDir()
+	var logged bytes.Buffer
+	q := newQueue(t, dir, jobs.Config{Workers: 1, ErrorLog: log.New(&logged, "", 0)})
+
+	job, err := q.Submit(hardInput, parser.FormatJSON)
+	if err != nil {
+		t.Fatalf("Expected no error submitting, got %v", err)
+	}
+	waitFor(t, q, job.ID, jobs.StatusRunning)
+
+	// The worker can no longer save the job once it is cancelled
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatalf("Failed to remove the store: %v", err)
+	}
+	if _, err := q.Cancel(job.ID); err != nil {
+		t.Fatalf("Expected no error cancelling, got %v", err)
+	}
+	waitFor(t, q, job.ID, jobs.StatusCancelled)
+	q.Close()
+
+	if !strings.Contains(logged.String(), "cannot save job "+job.ID) {
+		t.Errorf("Expected the failed save to be logged, got %q", logged.String())
+	}
+}
+
+func TestQueueSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+
+	q := newQueue(t, dir, jobs.Config{})
+	done, err := q.Submit("I I", parser.FormatAuto)
+	if err != nil {
+		t.Fatalf("Expected no error submitting, got %v", err)
+	}
+	waitFor(t, q, done.ID, jobs.StatusDone)
+
+	interrupted, err := q.Submit(hardInput, parser.FormatJSON)
+	if err != nil {
+		t.Fatalf("Expected no error submitting, got %v", err)
+	}
+	waitFor(t, q, interrupted.ID, jobs.StatusRunning)
+	q.Close()
+
+	q = newQueue(t, dir, jobs.Config{Timeout: 10 * time.Millisecond})
+	defer q.Close()
+
+	if job, err := q.Get(done.ID); err != nil || job.Result == nil || job.Result.Size != 4 {
+		t.Errorf("Expected stored result after restart, got %+v (%v)", job, err)
+	}
+
+	// The interrupted job runs again and now hits the timeout
+	job := waitFor(t, q, interrupted.ID, jobs.StatusFailed)
+	if job.Error == "" {
+		t.Error("Expected an error message for the timed out job")
+	}
+}
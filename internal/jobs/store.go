@@ -0,0 +1,83 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileStore persists jobs as one JSON file each in a directory, so
+// results survive restarts
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a store in dir, creating the directory if needed
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("cannot create job directory: %v", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Save writes a job atomically, replacing any previous version
+func (s *FileStore) Save(job *Job) error {
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot encode job %s: %v", job.ID, err)
+	}
+
+	tmp, err := os.CreateTemp(s.dir, job.ID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("cannot save job %s: %v", job.ID, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("cannot save job %s: %v", job.ID, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("cannot save job %s: %v", job.ID, err)
+	}
+
+	if err := os.Rename(tmp.Name(), s.path(job.ID)); err != nil {
+		return fmt.Errorf("cannot save job %s: %v", job.ID, err)
+	}
+	return nil
+}
+
+// LoadAll reads every stored job
+func (s *FileStore) LoadAll() ([]*Job, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read job directory: %v", err)
+	}
+
+	var jobs []*Job
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("cannot read job %s: %v", entry.Name(), err)
+		}
+
+		var job Job
+		if err := json.Unmarshal(data, &job); err != nil {
+			return nil, fmt.Errorf("cannot decode job %s: %v", entry.Name(), err)
+		}
+		jobs = append(jobs, &job)
+	}
+
+	return jobs, nil
+}
+
+// path returns the file holding a job
+func (s *FileStore) path(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
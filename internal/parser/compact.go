@@ -0,0 +1,159 @@
+package parser
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// ParseCompact parses the compact piece-name format, where pieces are
+// listed by letter separated by whitespace, for example:
+//
+//	I O T T@90 S L x3 Z
+//
+// Each token is a standard tetromino letter, optionally followed by a
+// rotation suffix "@DEG" (0, 90, 180 or 270 degrees clockwise) and a
+// multiplicity suffix "xN". A standalone "xN" token repeats the piece
+// before it, so "L x3" and "Lx3" are equivalent. A final "=X,Y" suffix
+// pins a single piece at that position in its given rotation, e.g.
+// "T@90=2,0". An input may hold at most MaxPieces pieces.
+func ParseCompact(r io.Reader, filename string) ([]*tetromino.Tetromino, error) {
+	scanner := bufio.NewScanner(r)
+	var tetrominoes []*tetromino.Tetromino
+	var currentID rune = 'A'
+	lineNum := 0
+
+	// last holds the most recent token's piece so a standalone "xN" can
+	// repeat it
+	var last *tetromino.Tetromino
+
+	add := func(base *tetromino.Tetromino, count int) error {
+		if count > MaxPieces-len(tetrominoes) {
+			return NewParseError(tooManyPieces, lineNum, filename)
+		}
+		for i := 0; i < count; i++ {
+			tetro := base.Clone()
+			tetro.ID = currentID
+			tetrominoes = append(tetrominoes, tetro)
+			currentID++
+		}
+		return nil
+	}
+
+	for scanner.Scan() {
+		lineNum++
+
+		for _, token := range strings.Fields(scanner.Text()) {
+			if strings.HasPrefix(token, "x") {
+				if last == nil {
+					return nil, NewParseError(fmt.Sprintf("multiplicity %q has no piece before it", token), lineNum, filename)
+				}
+				if last.Pin != nil {
+					return nil, NewParseError(fmt.Sprintf("pinned piece cannot be repeated by %q", token), lineNum, filename)
+				}
+				count, err := parseMultiplicity(token[1:])
+				if err != nil {
+					return nil, NewParseError(err.Error(), lineNum, filename)
+				}
+				// The piece itself was already added once
+				if err := add(last, count-1); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			base, count, err := parseCompactToken(token)
+			if err != nil {
+				return nil, NewParseError(err.Error(), lineNum, filename)
+			}
+			if err := add(base, count); err != nil {
+				return nil, err
+			}
+			last = base
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, NewParseError(fmt.Sprintf("error reading file: %v", err), 0, filename)
+	}
+
+	if len(tetrominoes) == 0 {
+		return nil, NewParseError("no valid tetrominoes found in file", 0, filename)
+	}
+
+	return tetrominoes, nil
+}
+
+// parseCompactToken parses a single LETTER[@DEG][xN][=X,Y] token
+func parseCompactToken(token string) (*tetromino.Tetromino, int, error) {
+	kind, ok := tetromino.ParseKind(token[:1])
+	if !ok {
+		return nil, 0, fmt.Errorf("unknown piece %q", token)
+	}
+
+	rest, position, pinned := strings.Cut(token[1:], "=")
+	count := 1
+
+	if idx := strings.IndexByte(rest, 'x'); idx >= 0 {
+		n, err := parseMultiplicity(rest[idx+1:])
+		if err != nil {
+			return nil, 0, err
+		}
+		count = n
+		rest = rest[:idx]
+	}
+
+	turns := 0
+	if rest != "" {
+		if !strings.HasPrefix(rest, "@") {
+			return nil, 0, fmt.Errorf("invalid suffix %q in piece %q", rest, token)
+		}
+		degrees, err := atois(rest[1:])
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid rotation in piece %q: %v", token, err)
+		}
+		turns, err = rotationTurns(degrees[0])
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	base, err := tetromino.NewStandard(0, kind)
+	if err != nil {
+		return nil, 0, err
+	}
+	base.Shape = base.Orientation(turns).Shape
+
+	if pinned {
+		if count != 1 {
+			return nil, 0, fmt.Errorf("pinned piece %q cannot have a multiplicity", token)
+		}
+		xs, ys, found := strings.Cut(position, ",")
+		if !found {
+			return nil, 0, fmt.Errorf("invalid pin %q, expected =X,Y", position)
+		}
+		xy, err := atois(xs, ys)
+		if err != nil {
+			return nil, 0, fmt.Errorf("invalid pin in piece %q: %v", token, err)
+		}
+		base.Pin, err = newPin(xy[0], xy[1], 0)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+
+	return base, count, nil
+}
+
+// parseMultiplicity parses the N of an "xN" suffix
+func parseMultiplicity(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid multiplicity %q, expected a positive number", value)
+	}
+	return n, nil
+}
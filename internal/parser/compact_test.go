@@ -0,0 +1,177 @@
+package parser_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stkisengese/tetris-optimizer/internal/parser"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+func TestParseCompact(t *testing.T) {
+	content := "I O T T\nS L x3 Zx2\n"
+
+	tetrominoes, err := parser.Parse(strings.NewReader(content), "test.txt", parser.FormatCompact)
+	if err != nil {
+		t.Fatalf("Expected no error for compact input, got: %v", err)
+	}
+
+	expected := []tetromino.Kind{
+		tetromino.KindI, tetromino.KindO, tetromino.KindT, tetromino.KindT, tetromino.KindS,
+		tetromino.KindL, tetromino.KindL, tetromino.KindL, tetromino.KindZ, tetromino.KindZ,
+	}
+	if len(tetrominoes) != len(expected) {
+		t.Fatalf("Expected %d tetrominoes, got %d", len(expected), len(tetrominoes))
+	}
+
+	for i, tetro := range tetrominoes {
+		if tetro.Kind != expected[i] {
+			t.Errorf("Tetromino %d: expected kind %v, got %v", i, expected[i], tetro.Kind)
+		}
+		if tetro.ID != rune('A'+i) {
+			t.Errorf("Tetromino %d: expected ID %c, got %c", i, 'A'+i, tetro.ID)
+		}
+	}
+}
+
+func TestParseCompactRotation(t *testing.T) {
+	tetrominoes, err := parser.Parse(strings.NewReader("I@90x2"), "test.txt", parser.FormatCompact)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if len(tetrominoes) != 2 {
+		t.Fatalf("Expected 2 tetrominoes, got %d", len(tetrominoes))
+	}
+
+	for _, tetro := range tetrominoes {
+		if tetro.Shape.Width() != 1 || tetro.Shape.Height() != 4 {
+			t.Errorf("Expected a vertical I piece, got %dx%d", tetro.Shape.Width(), tetro.Shape.Height())
+		}
+	}
+}
+
+func TestParseCompactMatchesBlocks(t *testing.T) {
+	blocks := `....
+.##.
+.##.
+....
+
+###.
+.#..
+....
+....`
+
+	fromBlocks, err := parser.Parse(strings.NewReader(blocks), "test.txt", parser.FormatAuto)
+	if err != nil {
+		t.Fatalf("Expected no error for block input, got: %v", err)
+	}
+
+	fromCompact, err := parser.Parse(strings.NewReader("O T"), "test.txt", parser.FormatAuto)
+	if err != nil {
+		t.Fatalf("Expected no error for compact input, got: %v", err)
+	}
+
+	if len(fromBlocks) != len(fromCompact) {
+		t.Fatalf("Expected %d tetrominoes, got %d", len(fromBlocks), len(fromCompact))
+	}
+
+	for i := range fromBlocks {
+		if fromBlocks[i].ShapeKey() != fromCompact[i].ShapeKey() || fromBlocks[i].ID != fromCompact[i].ID {
+			t.Errorf("Tetromino %d differs between formats", i)
+		}
+	}
+}
+
+func TestParseCompactInvalid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{name: "Unknown letter", content: "I Q"},
+		{name: "Dangling multiplicity", content: "x3 I"},
+		{name: "Zero multiplicity", content: "Ix0"},
+		{name: "Bad rotation", content: "T@45"},
+		{name: "Bad suffix", content: "T!"},
+		{name: "Non-numeric rotation", content: "T@ab"},
+		{name: "Empty input", content: "  \n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parser.Parse(strings.NewReader(tc.content), "test.txt", parser.FormatCompact)
+			if err == nil {
+				t.Errorf("Expected error for %s, got nil", tc.name)
+			}
+		})
+	}
+}
+
+func TestTooManyPieces(t *testing.T) {
+	block := "##..\n##..\n....\n....\n"
+	pieces := strings.Repeat(`{"kind": "O"},`, parser.MaxPieces)
+	testCases := []struct {
+		name    string
+		content string
+		format  parser.Format
+	}{
+		{name: "Huge multiplicity", content: "I x50000000", format: parser.FormatCompact},
+		{name: "Multiplicity suffix", content: "Ix27", format: parser.FormatCompact},
+		{name: "Running total", content: "I x20 O x6 T", format: parser.FormatCompact},
+		{name: "Blocks", content: strings.Repeat(block+"\n", parser.MaxPieces) + block, format: parser.FormatBlocks},
+		{name: "JSON", content: `{"pieces": [` + pieces + `{"kind": "O"}]}`, format: parser.FormatJSON},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parser.Parse(strings.NewReader(tc.content), "test.txt", tc.format)
+			var parseErr *parser.ParseError
+			if !errors.As(err, &parseErr) || !strings.Contains(err.Error(), "too many pieces") {
+				t.Errorf("Expected a too many pieces parse error, got %v", err)
+			}
+		})
+	}
+
+	// Exactly MaxPieces is fine, and labelled A to Z
+	tetrominoes, err := parser.Parse(strings.NewReader("I x20 O x6"), "test.txt", parser.FormatCompact)
+	if err != nil {
+		t.Fatalf("Expected no error for %d pieces, got %v", parser.MaxPieces, err)
+	}
+	if last := tetrominoes[len(tetrominoes)-1].ID; len(tetrominoes) != parser.MaxPieces || last != 'Z' {
+		t.Errorf("Expected %d pieces ending with Z, got %d ending with %c", parser.MaxPieces, len(tetrominoes), last)
+	}
+}
+
+func TestDetectFormat(t *testing.T) {
+	if f := parser.DetectFormat([]byte("#...\n#...\n#...\n#...\n")); f != parser.FormatBlocks {
+		t.Errorf("Expected blocks format, got %s", f)
+	}
+
+	if f := parser.DetectFormat([]byte("I O T x2\n")); f != parser.FormatCompact {
+		t.Errorf("Expected compact format, got %s", f)
+	}
+}
+
+func TestParseCompactPinned(t *testing.T) {
+	tetrominoes, err := parser.Parse(strings.NewReader("T@90=2,1 O"), "test.txt", parser.FormatCompact)
+	if err != nil {
+		t.Fatalf("Expected no error, got: %v", err)
+	}
+
+	if pin := tetrominoes[0].Pin; pin == nil || pin.X != 2 || pin.Y != 1 || pin.Rotation != 0 {
+		t.Errorf("Expected T pinned at (2, 1), got %+v", pin)
+	}
+	if tetrominoes[0].Shape.Width() != 2 || tetrominoes[0].Shape.Height() != 3 {
+		t.Errorf("Expected the pinned T to be turned upright, got %dx%d", tetrominoes[0].Shape.Width(), tetrominoes[0].Shape.Height())
+	}
+	if tetrominoes[1].Pin != nil {
+		t.Errorf("Expected O not to be pinned, got %+v", tetrominoes[1].Pin)
+	}
+
+	for _, content := range []string{"T=1", "T=a,1", "Tx2=0,0", "T=0,0 x2"} {
+		if _, err := parser.Parse(strings.NewReader(content), "test.txt", parser.FormatCompact); err == nil {
+			t.Errorf("Expected error for %q, got nil", content)
+		}
+	}
+}
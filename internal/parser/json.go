@@ -0,0 +1,318 @@
+package parser
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// jsonPuzzle is the top-level JSON input document
+type jsonPuzzle struct {
+	Pieces  []jsonPiece  `json:"pieces"`
+	Board   *jsonBoard   `json:"board"`
+	Options *jsonOptions `json:"options"`
+}
+
+// jsonPiece describes one piece. Exactly one of Kind, Cells or Grid
+// must be given.
+type jsonPiece struct {
+	ID           string   `json:"id"`
+	Kind         string   `json:"kind"`
+	Cells        [][]int  `json:"cells"`
+	Grid         []string `json:"grid"`
+	Orientations []int    `json:"orientations"`
+	Pin          *jsonPin `json:"pin"`
+}
+
+// jsonPin fixes a piece at a board position in the given clockwise
+// orientation, in degrees
+type jsonPin struct {
+	X           int `json:"x"`
+	Y           int `json:"y"`
+	Orientation int `json:"orientation"`
+}
+
+// jsonBoard fixes the board by size or by a mask of usable cells
+type jsonBoard struct {
+	Size int      `json:"size"`
+	Mask []string `json:"mask"`
+}
+
+// jsonOptions carries solver settings
+type jsonOptions struct {
+	MaxSize int `json:"max_size"`
+}
+
+// ParseJSON parses the structured JSON input format, for example:
+//
+//	{
+//	  "pieces": [
+//	    {"id": "A", "cells": [[0,0],[1,0],[2,0],[3,0]]},
+//	    {"grid": ["#...", "#...", "##..", "...."], "orientations": [0, 90]},
+//	    {"kind": "T"},
+//	    {"kind": "O", "pin": {"x": 2, "y": 2, "orientation": 0}}
+//	  ],
+//	  "board": {"mask": ["....", ".#..", "....", "...."]},
+//	  "options": {"max_size": 8}
+//	}
+//
+// Orientations are clockwise degrees relative to the piece as given. A
+// pinned piece is placed at x, y before the search starts.
+// Board masks use '.' for usable cells and '#' for blocked ones.
+func ParseJSON(r io.Reader, filename string) (*Puzzle, error) {
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	var doc jsonPuzzle
+	if err := decoder.Decode(&doc); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			return nil, NewPathError(fmt.Sprintf("expected %v, got %s", typeErr.Type, typeErr.Value), fieldPath(typeErr.Field), filename)
+		}
+		return nil, NewPathError(fmt.Sprintf("invalid JSON: %v", err), "$", filename)
+	}
+
+	if len(doc.Pieces) == 0 {
+		return nil, NewPathError("no valid tetrominoes found in file", "pieces", filename)
+	}
+	if len(doc.Pieces) > MaxPieces {
+		return nil, NewPathError(tooManyPieces, "pieces", filename)
+	}
+
+	puzzle := &Puzzle{Pieces: make([]*tetromino.Tetromino, len(doc.Pieces))}
+
+	// Explicit IDs are collected first so default IDs can skip them
+	ids := make([]rune, len(doc.Pieces))
+	seen := make(map[rune]string)
+	for i, piece := range doc.Pieces {
+		if piece.ID == "" {
+			continue
+		}
+		path := fmt.Sprintf("pieces[%d]", i)
+		r, size := utf8.DecodeRuneInString(piece.ID)
+		if size != len(piece.ID) || r == '.' || r == '#' {
+			return nil, NewPathError(fmt.Sprintf("id %q must be a single character other than '.' or '#'", piece.ID), path+".id", filename)
+		}
+		if other, ok := seen[r]; ok {
+			return nil, NewPathError(fmt.Sprintf("duplicate id %q, already used by %s", r, other), path+".id", filename)
+		}
+		ids[i] = r
+		seen[r] = path
+	}
+
+	next := 'A'
+	for i, piece := range doc.Pieces {
+		path := fmt.Sprintf("pieces[%d]", i)
+
+		id := ids[i]
+		if id == 0 {
+			for seen[next] != "" {
+				next++
+			}
+			id = next
+			seen[id] = path
+		}
+
+		tetro, err := jsonTetromino(piece, id, path, filename)
+		if err != nil {
+			return nil, err
+		}
+		puzzle.Pieces[i] = tetro
+	}
+
+	if doc.Board != nil {
+		if err := applyJSONBoard(puzzle, doc.Board, filename); err != nil {
+			return nil, err
+		}
+	}
+
+	if doc.Options != nil {
+		if doc.Options.MaxSize < 0 {
+			return nil, NewPathError("max_size must not be negative", "options.max_size", filename)
+		}
+		puzzle.Options.MaxSize = doc.Options.MaxSize
+	}
+
+	return puzzle, nil
+}
+
+// jsonTetromino builds one piece from whichever description it uses
+func jsonTetromino(piece jsonPiece, id rune, path, filename string) (*tetromino.Tetromino, error) {
+	given := 0
+	for _, set := range []bool{piece.Kind != "", piece.Cells != nil, piece.Grid != nil} {
+		if set {
+			given++
+		}
+	}
+	if given != 1 {
+		return nil, NewPathError("piece must have exactly one of kind, cells or grid", path, filename)
+	}
+
+	var tetro *tetromino.Tetromino
+	var err error
+
+	switch {
+	case piece.Kind != "":
+		kind, ok := tetromino.ParseKind(piece.Kind)
+		if !ok {
+			return nil, NewPathError(fmt.Sprintf("unknown piece kind %q", piece.Kind), path+".kind", filename)
+		}
+		tetro, err = tetromino.NewStandard(id, kind)
+		if err != nil {
+			return nil, NewPathError(err.Error(), path+".kind", filename)
+		}
+
+	case piece.Cells != nil:
+		rows, err := cellsToRows(piece.Cells, path+".cells", filename)
+		if err != nil {
+			return nil, err
+		}
+		tetro, err = processTetromino(rows, id, filename)
+		if err != nil {
+			return nil, withPath(err, path+".cells")
+		}
+
+	default:
+		tetro, err = processTetromino(piece.Grid, id, filename)
+		if err != nil {
+			return nil, withPath(err, path+".grid")
+		}
+	}
+
+	for j, degrees := range piece.Orientations {
+		turns, err := rotationTurns(degrees)
+		if err != nil {
+			return nil, NewPathError(err.Error(), fmt.Sprintf("%s.orientations[%d]", path, j), filename)
+		}
+		tetro.Rotations = append(tetro.Rotations, turns)
+	}
+
+	if piece.Pin != nil {
+		pin, err := newPin(piece.Pin.X, piece.Pin.Y, piece.Pin.Orientation)
+		if err != nil {
+			return nil, NewPathError(err.Error(), path+".pin", filename)
+		}
+		tetro.Pin = pin
+	}
+
+	return tetro, nil
+}
+
+// cellsToRows draws a list of [x, y] cells as a 4x4 block so it can be
+// validated like any other piece
+func cellsToRows(cells [][]int, path, filename string) ([]string, error) {
+	if len(cells) != 4 {
+		return nil, NewPathError(fmt.Sprintf("tetromino must have exactly 4 blocks, got %d", len(cells)), path, filename)
+	}
+
+	minX, minY := cells[0], cells[0]
+	for j, cell := range cells {
+		if len(cell) != 2 {
+			return nil, NewPathError("cell must be an [x, y] pair", fmt.Sprintf("%s[%d]", path, j), filename)
+		}
+		if cell[0] < minX[0] {
+			minX = cell
+		}
+		if cell[1] < minY[1] {
+			minY = cell
+		}
+	}
+
+	var block [4][4]byte
+	for y := range block {
+		for x := range block[y] {
+			block[y][x] = '.'
+		}
+	}
+
+	for j, cell := range cells {
+		x, y := cell[0]-minX[0], cell[1]-minY[1]
+		if x >= 4 || y >= 4 {
+			return nil, NewPathError("tetromino blocks must be connected", fmt.Sprintf("%s[%d]", path, j), filename)
+		}
+		if block[y][x] == '#' {
+			return nil, NewPathError(fmt.Sprintf("duplicate cell [%d, %d]", cell[0], cell[1]), fmt.Sprintf("%s[%d]", path, j), filename)
+		}
+		block[y][x] = '#'
+	}
+
+	rows := make([]string, 4)
+	for y, row := range block {
+		rows[y] = string(row[:])
+	}
+	return rows, nil
+}
+
+// applyJSONBoard validates the board spec and stores it in the options
+func applyJSONBoard(puzzle *Puzzle, board *jsonBoard, filename string) error {
+	if board.Size < 0 {
+		return NewPathError("size must not be negative", "board.size", filename)
+	}
+	puzzle.Options.Size = board.Size
+
+	if board.Mask == nil {
+		return nil
+	}
+
+	size := len(board.Mask)
+	if size == 0 {
+		return NewPathError("mask must not be empty", "board.mask", filename)
+	}
+	if board.Size != 0 && board.Size != size {
+		return NewPathError(fmt.Sprintf("size %d does not match %dx%d mask", board.Size, size, size), "board.size", filename)
+	}
+
+	mask := make([][]bool, size)
+	for y, row := range board.Mask {
+		path := fmt.Sprintf("board.mask[%d]", y)
+		if len(row) != size {
+			return NewPathError(fmt.Sprintf("mask must be square: row has %d cells, expected %d", len(row), size), path, filename)
+		}
+
+		mask[y] = make([]bool, size)
+		for x := 0; x < size; x++ {
+			switch row[x] {
+			case '.':
+			case '#':
+				mask[y][x] = true
+			default:
+				return NewPathError(fmt.Sprintf("invalid character '%c' at position %d", row[x], x), path, filename)
+			}
+		}
+	}
+
+	puzzle.Options.Mask = mask
+	return nil
+}
+
+// fieldPath rewrites a decoder field path such as "pieces.0.kind" into
+// the bracket form "pieces[0].kind" used by other errors
+func fieldPath(field string) string {
+	var builder strings.Builder
+	for i, part := range strings.Split(field, ".") {
+		if _, err := strconv.Atoi(part); err == nil {
+			fmt.Fprintf(&builder, "[%s]", part)
+			continue
+		}
+		if i > 0 {
+			builder.WriteString(".")
+		}
+		builder.WriteString(part)
+	}
+	return builder.String()
+}
+
+// withPath attaches a JSON path to a parse error
+func withPath(err error, path string) error {
+	var parseErr *ParseError
+	if errors.As(err, &parseErr) {
+		parseErr.Path = path
+	}
+	return err
+}
@@ -0,0 +1,129 @@
+package parser_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stkisengese/tetris-optimizer/internal/parser"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+func TestParseJSON(t *testing.T) {
+	content := `{
+  "pieces": [
+    {"id": "X", "cells": [[5,5],[6,5],[7,5],[8,5]]},
+    {"grid": ["#...", "#...", "##..", "...."], "orientations": [0, 90]},
+    {"kind": "T", "pin": {"x": 1, "y": 2, "orientation": 180}}
+  ],
+  "board": {"mask": ["....", ".#..", "....", "...."]},
+  "options": {"max_size": 6}
+}`
+
+	puzzle, err := parser.ParsePuzzle(strings.NewReader(content), "test.json", parser.FormatAuto)
+	if err != nil {
+		t.Fatalf("Expected no error for JSON input, got: %v", err)
+	}
+
+	if len(puzzle.Pieces) != 3 {
+		t.Fatalf("Expected 3 pieces, got %d", len(puzzle.Pieces))
+	}
+
+	expectedIDs := []rune{'X', 'A', 'B'}
+	expectedKinds := []tetromino.Kind{tetromino.KindI, tetromino.KindL, tetromino.KindT}
+	for i, tetro := range puzzle.Pieces {
+		if tetro.ID != expectedIDs[i] {
+			t.Errorf("Piece %d: expected ID %c, got %c", i, expectedIDs[i], tetro.ID)
+		}
+		if tetro.Kind != expectedKinds[i] {
+			t.Errorf("Piece %d: expected kind %v, got %v", i, expectedKinds[i], tetro.Kind)
+		}
+	}
+
+	if rotations := puzzle.Pieces[1].Rotations; len(rotations) != 2 || rotations[1] != 1 {
+		t.Errorf("Expected rotations [0 1], got %v", rotations)
+	}
+
+	if pin := puzzle.Pieces[2].Pin; pin == nil || *pin != (tetromino.Pin{X: 1, Y: 2, Rotation: 2}) {
+		t.Errorf("Expected T pinned at (1, 2) turned twice, got %+v", pin)
+	}
+
+	if len(puzzle.Options.Mask) != 4 || !puzzle.Options.Mask[1][1] || puzzle.Options.Mask[0][0] {
+		t.Errorf("Expected 4x4 mask with (1,1) blocked, got %v", puzzle.Options.Mask)
+	}
+
+	if puzzle.Options.MaxSize != 6 {
+		t.Errorf("Expected max size 6, got %d", puzzle.Options.MaxSize)
+	}
+}
+
+func TestParseJSONDefaultIDs(t *testing.T) {
+	content := `{"pieces": [{"id": "B", "kind": "I"}, {"kind": "O"}, {"kind": "T"}]}`
+
+	puzzle, err := parser.ParseJSON(strings.NewReader(content), "test.json")
+	if err != nil {
+		t.Fatalf("Expected default IDs to skip explicit ones, got: %v", err)
+	}
+
+	expectedIDs := []rune{'B', 'A', 'C'}
+	for i, tetro := range puzzle.Pieces {
+		if tetro.ID != expectedIDs[i] {
+			t.Errorf("Piece %d: expected ID %c, got %c", i, expectedIDs[i], tetro.ID)
+		}
+	}
+}
+
+func TestParseJSONErrorPaths(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		path    string
+	}{
+		{name: "Syntax error", content: `{"pieces": [`, path: "$"},
+		{name: "Unknown field", content: `{"pieces": [{"kind": "T"}], "extra": 1}`, path: "$"},
+		{name: "No pieces", content: `{"pieces": []}`, path: "pieces"},
+		{name: "Wrong type", content: `{"pieces": [{"kind": 3}]}`, path: "pieces[0].kind"},
+		{name: "No description", content: `{"pieces": [{"id": "A"}]}`, path: "pieces[0]"},
+		{name: "Two descriptions", content: `{"pieces": [{"kind": "T", "grid": ["####", "....", "....", "...."]}]}`, path: "pieces[0]"},
+		{name: "Unknown kind", content: `{"pieces": [{"kind": "T"}, {"kind": "Q"}]}`, path: "pieces[1].kind"},
+		{name: "Three cells", content: `{"pieces": [{"cells": [[0,0],[1,0],[2,0]]}]}`, path: "pieces[0].cells"},
+		{name: "Bad pair", content: `{"pieces": [{"cells": [[0,0],[1,0],[2,0],[3]]}]}`, path: "pieces[0].cells[3]"},
+		{name: "Far apart", content: `{"pieces": [{"cells": [[0,0],[1,0],[2,0],[9,9]]}]}`, path: "pieces[0].cells[3]"},
+		{name: "Disconnected cells", content: `{"pieces": [{"cells": [[0,0],[1,0],[0,2],[1,2]]}]}`, path: "pieces[0].cells"},
+		{name: "Bad grid", content: `{"pieces": [{"grid": ["#...", "#...", "#.x.", "#..."]}]}`, path: "pieces[0].grid"},
+		{name: "Duplicate id", content: `{"pieces": [{"id": "A", "kind": "T"}, {"id": "A", "kind": "O"}]}`, path: "pieces[1].id"},
+		{name: "Long id", content: `{"pieces": [{"id": "AB", "kind": "T"}]}`, path: "pieces[0].id"},
+		{name: "Bad orientation", content: `{"pieces": [{"kind": "T", "orientations": [0, 45]}]}`, path: "pieces[0].orientations[1]"},
+		{name: "Bad pin", content: `{"pieces": [{"kind": "T", "pin": {"x": -1, "y": 0}}]}`, path: "pieces[0].pin"},
+		{name: "Ragged mask", content: `{"pieces": [{"kind": "T"}], "board": {"mask": ["..", "."]}}`, path: "board.mask[1]"},
+		{name: "Size mismatch", content: `{"pieces": [{"kind": "T"}], "board": {"size": 4, "mask": ["...", "...", "..."]}}`, path: "board.size"},
+		{name: "Negative max", content: `{"pieces": [{"kind": "T"}], "options": {"max_size": -1}}`, path: "options.max_size"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := parser.ParsePuzzle(strings.NewReader(tc.content), "test.json", parser.FormatJSON)
+			if err == nil {
+				t.Fatalf("Expected error for %s, got nil", tc.name)
+			}
+
+			var parseErr *parser.ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("Expected a ParseError, got %T: %v", err, err)
+			}
+
+			if parseErr.Path != tc.path {
+				t.Errorf("Expected error at %q, got %q (%v)", tc.path, parseErr.Path, err)
+			}
+		})
+	}
+}
+
+func TestPathErrorMessage(t *testing.T) {
+	err := parser.NewPathError("test error", "pieces[2].cells", "test.json")
+
+	expected := "parse error at pieces[2].cells: test error"
+	if err.Error() != expected {
+		t.Errorf("Expected error message %q, got %q", expected, err.Error())
+	}
+}
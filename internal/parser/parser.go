@@ -2,9 +2,14 @@ package parser
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
+	"strings"
 
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
 	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
 )
 
@@ -13,9 +18,15 @@ type ParseError struct {
 	Message string
 	Line    int
 	File    string
+
+	// Path locates the error inside a JSON document, e.g. "pieces[2].cells"
+	Path string
 }
 
 func (e *ParseError) Error() string {
+	if e.Path != "" {
+		return fmt.Sprintf("parse error at %s: %s", e.Path, e.Message)
+	}
 	if e.Line > 0 {
 		return fmt.Sprintf("parse error at line %d: %s", e.Line, e.Message)
 	}
@@ -31,20 +42,138 @@ func NewParseError(message string, line int, file string) *ParseError {
 	}
 }
 
-// ReadFile reads and parses tetromino definitions from a file
+// NewPathError creates a parse error located by a JSON path
+func NewPathError(message string, path string, file string) *ParseError {
+	return &ParseError{
+		Message: message,
+		File:    file,
+		Path:    path,
+	}
+}
+
+// MaxPieces is the most pieces an input may hold in any format: one per
+// letter from A to Z, the labels pieces get by default
+const MaxPieces = 26
+
+// tooManyPieces is the message of the error for inputs over MaxPieces
+var tooManyPieces = fmt.Sprintf("too many pieces: at most %d are allowed", MaxPieces)
+
+// Puzzle is a parsed input: the pieces plus any board and solver
+// settings the input format can express
+type Puzzle struct {
+	Pieces  []*tetromino.Tetromino
+	Options solver.Options
+}
+
+// Format selects the syntax of an input file
+type Format string
+
+const (
+	// FormatAuto detects the format from the file contents
+	FormatAuto Format = "auto"
+
+	// FormatBlocks is the 4x4 block format, one drawing per piece
+	FormatBlocks Format = "blocks"
+
+	// FormatCompact is the piece-name format, e.g. "I O T x2"
+	FormatCompact Format = "compact"
+
+	// FormatJSON is the structured JSON format
+	FormatJSON Format = "json"
+)
+
+// ParseFormat validates a format name such as "auto" or "compact"
+func ParseFormat(name string) (Format, error) {
+	switch f := Format(name); f {
+	case FormatAuto, FormatBlocks, FormatCompact, FormatJSON:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown input format %q", name)
+}
+
+// ReadFile reads and parses tetromino definitions from a file,
+// detecting its format automatically
 func ReadFile(filename string) ([]*tetromino.Tetromino, error) {
+	return ReadFileFormat(filename, FormatAuto)
+}
+
+// ReadFileFormat reads and parses tetromino definitions from a file in
+// the given format
+func ReadFileFormat(filename string, format Format) ([]*tetromino.Tetromino, error) {
+	puzzle, err := ReadPuzzle(filename, format)
+	if err != nil {
+		return nil, err
+	}
+	return puzzle.Pieces, nil
+}
+
+// ReadPuzzle reads and parses a full puzzle, including any board and
+// solver settings, from a file in the given format
+func ReadPuzzle(filename string, format Format) (*Puzzle, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		return nil, NewParseError(fmt.Sprintf("cannot open file: %v", err), 0, filename)
 	}
 	defer file.Close()
 
-	return ParseTetrominoes(file, filename)
+	return ParsePuzzle(file, filename, format)
 }
 
-// ParseTetrominoes parses tetrominoes from a reader
-func ParseTetrominoes(file *os.File, filename string) ([]*tetromino.Tetromino, error) {
-	scanner := bufio.NewScanner(file)
+// Parse parses tetrominoes from a reader in the given format
+func Parse(r io.Reader, filename string, format Format) ([]*tetromino.Tetromino, error) {
+	puzzle, err := ParsePuzzle(r, filename, format)
+	if err != nil {
+		return nil, err
+	}
+	return puzzle.Pieces, nil
+}
+
+// ParsePuzzle parses a full puzzle from a reader in the given format.
+// Only the JSON format carries board and solver settings; the others
+// leave Options zero.
+func ParsePuzzle(r io.Reader, filename string, format Format) (*Puzzle, error) {
+	var pieces []*tetromino.Tetromino
+	var err error
+
+	switch format {
+	case FormatBlocks:
+		pieces, err = ParseTetrominoes(r, filename)
+	case FormatCompact:
+		pieces, err = ParseCompact(r, filename)
+	case FormatJSON:
+		return ParseJSON(r, filename)
+	case FormatAuto:
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, NewParseError(fmt.Sprintf("error reading file: %v", err), 0, filename)
+		}
+		return ParsePuzzle(bytes.NewReader(data), filename, DetectFormat(data))
+	default:
+		return nil, NewParseError(fmt.Sprintf("unknown input format %q", format), 0, filename)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return &Puzzle{Pieces: pieces}, nil
+}
+
+// DetectFormat guesses the format of an input. A leading '{' means
+// JSON; any '#', '.' or "pin" line means the file is in the block
+// format; otherwise it lists piece names.
+func DetectFormat(data []byte) Format {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		return FormatJSON
+	}
+	if bytes.ContainsAny(data, "#.") || bytes.HasPrefix(data, []byte("pin")) || bytes.Contains(data, []byte("\npin")) {
+		return FormatBlocks
+	}
+	return FormatCompact
+}
+
+// ParseTetrominoes parses tetrominoes in the 4x4 block format from a reader
+func ParseTetrominoes(r io.Reader, filename string) ([]*tetromino.Tetromino, error) {
+	scanner := bufio.NewScanner(r)
 	var tetrominoes []*tetromino.Tetromino
 	var currentGrid []string
 	var currentID rune = 'A'
@@ -54,7 +183,10 @@ func ParseTetrominoes(file *os.File, filename string) ([]*tetromino.Tetromino, e
 
 		if line == "" {
 			if len(currentGrid) != 0 {
-				tetro, err := processTetromino(currentGrid, currentID, filename)
+				if len(tetrominoes) == MaxPieces {
+					return nil, NewParseError(tooManyPieces, 0, filename)
+				}
+				tetro, err := processBlock(currentGrid, currentID, filename)
 				if err != nil {
 					return nil, err
 				}
@@ -70,7 +202,10 @@ func ParseTetrominoes(file *os.File, filename string) ([]*tetromino.Tetromino, e
 
 	// Process last tetromino if file doesn't end with a newline
 	if len(currentGrid) != 0 {
-		tetro, err := processTetromino(currentGrid, currentID, filename)
+		if len(tetrominoes) == MaxPieces {
+			return nil, NewParseError(tooManyPieces, 0, filename)
+		}
+		tetro, err := processBlock(currentGrid, currentID, filename)
 		if err != nil {
 			return nil, err
 		}
@@ -88,8 +223,92 @@ func ParseTetrominoes(file *os.File, filename string) ([]*tetromino.Tetromino, e
 	return tetrominoes, nil
 }
 
+// processBlock creates a tetromino from one block of the input. The
+// block may end with a "pin X Y [DEG]" line fixing the piece in place.
+func processBlock(lines []string, id rune, filename string) (*tetromino.Tetromino, error) {
+	var pin *tetromino.Pin
+	if last := len(lines) - 1; last > 0 && strings.HasPrefix(lines[last], "pin") {
+		var err error
+		pin, err = parsePinLine(lines[last])
+		if err != nil {
+			return nil, NewParseError(err.Error(), 0, filename)
+		}
+		lines = lines[:last]
+	}
+
+	tetro, err := processTetromino(lines, id, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	tetro.Pin = pin
+	return tetro, nil
+}
+
+// parsePinLine parses a "pin X Y [DEG]" line
+func parsePinLine(line string) (*tetromino.Pin, error) {
+	fields := strings.Fields(line)
+	if fields[0] != "pin" || len(fields) < 3 || len(fields) > 4 {
+		return nil, fmt.Errorf("invalid pin %q, expected \"pin X Y [DEG]\"", line)
+	}
+
+	degrees := "0"
+	if len(fields) == 4 {
+		degrees = fields[3]
+	}
+
+	values, err := atois(fields[1], fields[2], degrees)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pin %q: %v", line, err)
+	}
+
+	return newPin(values[0], values[1], values[2])
+}
+
+// newPin validates a pin position and a clockwise rotation in degrees
+func newPin(x, y, degrees int) (*tetromino.Pin, error) {
+	if x < 0 || y < 0 {
+		return nil, fmt.Errorf("invalid pin position (%d, %d), expected non-negative numbers", x, y)
+	}
+
+	turns, err := rotationTurns(degrees)
+	if err != nil {
+		return nil, err
+	}
+
+	return &tetromino.Pin{X: x, Y: y, Rotation: turns}, nil
+}
+
+// rotationTurns converts 0, 90, 180 or 270 degrees into quarter turns
+func rotationTurns(degrees int) (int, error) {
+	if degrees < 0 || degrees%90 != 0 || degrees >= 360 {
+		return 0, fmt.Errorf("invalid rotation %d, expected 0, 90, 180 or 270", degrees)
+	}
+	return degrees / 90, nil
+}
+
+// atois converts each string to an int
+func atois(values ...string) ([]int, error) {
+	result := make([]int, len(values))
+	for i, v := range values {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", v)
+		}
+		result[i] = n
+	}
+	return result, nil
+}
+
 // validateAndCreateTetromino validates a tetromino and creates it
 func processTetromino(lines []string, id rune, filename string) (*tetromino.Tetromino, error) {
+	// A block may name a standard piece by letter instead of drawing it
+	if len(lines) == 1 {
+		if kind, ok := tetromino.ParseKind(lines[0]); ok {
+			return tetromino.NewStandard(id, kind)
+		}
+	}
+
 	if len(lines) != 4 {
 		return nil, NewParseError(fmt.Sprintf("tetromino must be 4x4 grid, got %d lines", len(lines)), 0, filename)
 	}
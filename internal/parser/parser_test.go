@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/stkisengese/tetris-optimizer/internal/parser"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
 )
 
 // Helper function to create temporary test files
@@ -270,3 +271,87 @@ func TestComplexValidTetromino(t *testing.T) {
 		})
 	}
 }
+
+func TestPiecesNamedByLetter(t *testing.T) {
+	content := `T
+
+#...
+#...
+##..
+....
+
+s`
+
+	tmpFile := createTempFile(t, content)
+
+	tetrominoes, err := parser.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatalf("Expected no error for letter-named pieces, got: %v", err)
+	}
+
+	expected := []tetromino.Kind{tetromino.KindT, tetromino.KindL, tetromino.KindS}
+	if len(tetrominoes) != len(expected) {
+		t.Fatalf("Expected %d tetrominoes, got %d", len(expected), len(tetrominoes))
+	}
+
+	for i, tetro := range tetrominoes {
+		if tetro.Kind != expected[i] {
+			t.Errorf("Tetromino %d: expected kind %v, got %v", i, expected[i], tetro.Kind)
+		}
+		if tetro.ID != rune('A'+i) {
+			t.Errorf("Tetromino %d: expected ID %c, got %c", i, 'A'+i, tetro.ID)
+		}
+	}
+}
+
+func TestUnknownPieceLetter(t *testing.T) {
+	tmpFile := createTempFile(t, "Q")
+
+	if _, err := parser.ReadFile(tmpFile); err == nil {
+		t.Error("Expected error for unknown piece letter, got nil")
+	}
+}
+
+func TestPinnedBlocks(t *testing.T) {
+	content := `#...
+#...
+##..
+....
+pin 1 0 90
+
+T
+pin 0 2
+
+O`
+
+	tmpFile := createTempFile(t, content)
+
+	tetrominoes, err := parser.ReadFile(tmpFile)
+	if err != nil {
+		t.Fatalf("Expected no error for pinned pieces, got: %v", err)
+	}
+
+	if len(tetrominoes) != 3 {
+		t.Fatalf("Expected 3 tetrominoes, got %d", len(tetrominoes))
+	}
+
+	expected := []*tetromino.Pin{{X: 1, Y: 0, Rotation: 1}, {X: 0, Y: 2, Rotation: 0}, nil}
+	for i, tetro := range tetrominoes {
+		switch {
+		case expected[i] == nil && tetro.Pin != nil:
+			t.Errorf("Tetromino %d: expected no pin, got %+v", i, *tetro.Pin)
+		case expected[i] != nil && (tetro.Pin == nil || *tetro.Pin != *expected[i]):
+			t.Errorf("Tetromino %d: expected pin %+v, got %+v", i, *expected[i], tetro.Pin)
+		}
+	}
+}
+
+func TestInvalidPin(t *testing.T) {
+	for _, content := range []string{"T\npin 1", "T\npin a 1", "T\npin -1 0", "T\npin 0 0 45", "T\npin 0 0 90 1"} {
+		tmpFile := createTempFile(t, content)
+
+		if _, err := parser.ReadFile(tmpFile); err == nil {
+			t.Errorf("Expected error for %q, got nil", content)
+		}
+	}
+}
@@ -0,0 +1,332 @@
+// Package play is an interactive mode in which the user packs the
+// pieces of a puzzle by hand. A Game holds the board and the pieces;
+// Run drives it from terminal key presses.
+package play
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/stkisengese/tetris-optimizer/internal/grid"
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// Game is a board being packed by hand
+type Game struct {
+	// Board holds the placed pieces, and any blocked cells of the puzzle
+	Board *grid.Grid
+
+	// Pieces are the puzzle pieces as given
+	Pieces []*tetromino.Tetromino
+
+	// Selected is the index of the piece being moved
+	Selected int
+
+	// CursorX and CursorY locate the top-left corner of the selected
+	// piece's bounding box
+	CursorX int
+	CursorY int
+
+	// Message is feedback on the last action
+	Message string
+
+	// oriented holds each piece in its current orientation
+	oriented []tetromino.Orientation
+	pinned   []bool
+
+	opts solver.Options
+}
+
+// New starts a game on a size x size board. Blocked cells of opts.Mask
+// are applied and pinned pieces are placed; they cannot be removed.
+// opts also selects the search used by Hint.
+func New(pieces []*tetromino.Tetromino, size int, opts solver.Options) (*Game, error) {
+	if len(pieces) == 0 {
+		return nil, fmt.Errorf("there are no pieces to play with")
+	}
+	if opts.Mask != nil && len(opts.Mask) != size {
+		return nil, fmt.Errorf("board size %d does not match %dx%d mask", size, len(opts.Mask), len(opts.Mask))
+	}
+
+	board, err := grid.NewGrid(size)
+	if err != nil {
+		return nil, err
+	}
+	for y, row := range opts.Mask {
+		for x, blocked := range row {
+			if blocked {
+				board.Block(x, y)
+			}
+		}
+	}
+
+	g := &Game{
+		Board:    board,
+		Pieces:   pieces,
+		oriented: make([]tetromino.Orientation, len(pieces)),
+		pinned:   make([]bool, len(pieces)),
+		opts:     opts,
+	}
+	for i, t := range pieces {
+		g.oriented[i] = t.Orientation(0)
+		if t.Pin == nil {
+			continue
+		}
+
+		p := grid.Placement{Piece: t.ID, Orientation: t.Orientation(t.Pin.Rotation), X: t.Pin.X, Y: t.Pin.Y}
+		if err := board.Place(p); err != nil {
+			return nil, fmt.Errorf("pinned piece %c does not fit: %v", t.ID, err)
+		}
+		g.oriented[i], g.pinned[i] = p.Orientation, true
+	}
+
+	g.Selected = -1
+	if !g.selectNext(1) {
+		g.Selected = 0
+	}
+	return g, nil
+}
+
+// Placed returns how many pieces are on the board
+func (g *Game) Placed() int {
+	return len(g.Board.Placements())
+}
+
+// Solved reports whether every piece is on the board
+func (g *Game) Solved() bool {
+	return g.Placed() == len(g.Pieces)
+}
+
+// Select makes the piece with the given ID the one being moved
+func (g *Game) Select(id rune) {
+	for i, t := range g.Pieces {
+		if t.ID == id {
+			g.Selected = i
+			g.Message = ""
+			return
+		}
+	}
+	g.Message = fmt.Sprintf("there is no piece %c", id)
+}
+
+// Cycle selects the next piece (delta 1) or the previous one (delta
+// -1), preferring pieces that are not yet placed
+func (g *Game) Cycle(delta int) {
+	g.Message = ""
+	if !g.selectNext(delta) {
+		g.Selected = (g.Selected + delta + len(g.Pieces)) % len(g.Pieces)
+	}
+}
+
+// selectNext selects the next unplaced piece in the given direction,
+// reporting whether there was one
+func (g *Game) selectNext(delta int) bool {
+	n := len(g.Pieces)
+	for step := 1; step <= n; step++ {
+		i := ((g.Selected+delta*step)%n + n) % n
+		if _, ok := g.placement(i); !ok {
+			g.Selected = i
+			return true
+		}
+	}
+	return false
+}
+
+// Rotate turns the selected piece a quarter turn clockwise, skipping
+// orientations its rotation restrictions rule out
+func (g *Game) Rotate() {
+	if g.pinned[g.Selected] {
+		g.Message = "pinned pieces cannot be turned"
+		return
+	}
+
+	t := g.Pieces[g.Selected]
+	turns := g.oriented[g.Selected].Turns
+	for i := 0; i < 4; i++ {
+		turns = (turns + 1) % 4
+		if len(t.Rotations) == 0 || slices.Contains(t.Rotations, turns) {
+			break
+		}
+	}
+	g.oriented[g.Selected] = t.Orientation(turns)
+	g.Message = ""
+	g.clampCursor()
+}
+
+// Move shifts the cursor, keeping the selected piece on the board
+func (g *Game) Move(dx, dy int) {
+	g.CursorX += dx
+	g.CursorY += dy
+	g.clampCursor()
+	g.Message = ""
+}
+
+// clampCursor keeps the selected piece's bounding box on the board
+func (g *Game) clampCursor() {
+	width, height := g.oriented[g.Selected].Width(), g.oriented[g.Selected].Height()
+	g.CursorX = max(0, min(g.CursorX, g.Board.Size-width))
+	g.CursorY = max(0, min(g.CursorY, g.Board.Size-height))
+}
+
+// Place puts the selected piece at the cursor. A piece already on the
+// board is moved there.
+func (g *Game) Place() error {
+	if g.pinned[g.Selected] {
+		return g.fail(fmt.Errorf("piece %c is pinned", g.Pieces[g.Selected].ID))
+	}
+
+	id := g.Pieces[g.Selected].ID
+	previous, moving := g.placement(g.Selected)
+	if moving {
+		g.Board.Remove(previous)
+	}
+
+	p := grid.Placement{Piece: id, Orientation: g.oriented[g.Selected], X: g.CursorX, Y: g.CursorY}
+	if err := g.Board.Place(p); err != nil {
+		if moving {
+			g.Board.Place(previous)
+		}
+		return g.fail(fmt.Errorf("piece %c does not fit at (%d, %d)", id, g.CursorX, g.CursorY))
+	}
+
+	g.Message = fmt.Sprintf("placed %c", id)
+	if g.Solved() {
+		g.Message = fmt.Sprintf("solved on a %dx%d board", g.Board.Size, g.Board.Size)
+		return nil
+	}
+	g.selectNext(1)
+	g.clampCursor()
+	return nil
+}
+
+// Remove takes the piece under the cursor off the board, or the
+// selected piece when the cursor cell is empty
+func (g *Game) Remove() error {
+	p, ok := g.Board.PlacementAt(g.CursorX, g.CursorY)
+	if !ok {
+		p, ok = g.placement(g.Selected)
+	}
+	if !ok {
+		return g.fail(fmt.Errorf("there is no piece to remove"))
+	}
+	i := g.index(p.Piece)
+	if g.pinned[i] {
+		return g.fail(fmt.Errorf("piece %c is pinned", p.Piece))
+	}
+
+	if err := g.Board.Remove(p); err != nil {
+		return g.fail(err)
+	}
+	g.Selected = i
+	g.clampCursor()
+	g.Message = fmt.Sprintf("removed %c", g.Pieces[i].ID)
+	return nil
+}
+
+// placement returns where piece i is on the board, if it is placed
+func (g *Game) placement(i int) (grid.Placement, bool) {
+	for _, p := range g.Board.Placements() {
+		if p.Piece == g.Pieces[i].ID {
+			return p, true
+		}
+	}
+	return grid.Placement{}, false
+}
+
+// index returns the index of the piece with the given ID
+func (g *Game) index(id rune) int {
+	return slices.IndexFunc(g.Pieces, func(t *tetromino.Tetromino) bool { return t.ID == id })
+}
+
+// Hint asks the solver whether the unplaced pieces still fit around the
+// placed ones on this board, and sets Message to the answer
+func (g *Game) Hint(ctx context.Context) (bool, error) {
+	var remaining []*tetromino.Tetromino
+	for i, t := range g.Pieces {
+		if _, ok := g.placement(i); !ok {
+			free := t.Clone()
+			free.Pin = nil
+			remaining = append(remaining, free)
+		}
+	}
+	if len(remaining) == 0 {
+		g.Message = "every piece is placed"
+		return true, nil
+	}
+
+	opts := solver.Options{Strategy: g.opts.Strategy, Order: g.opts.Order}
+	result, err := solver.Complete(ctx, g.Board, remaining, opts)
+	if err != nil {
+		return false, g.fail(fmt.Errorf("no hint: %w", err))
+	}
+	if !result.Success {
+		proof := result.Infeasible[0]
+		g.Message = fmt.Sprintf("hint: the remaining pieces cannot be completed on this %dx%d board (%s)", g.Board.Size, g.Board.Size, proof.Reason)
+		return false, nil
+	}
+	g.Message = "hint: the board can still be completed"
+	return true, nil
+}
+
+// fail records err as the message and returns it
+func (g *Game) fail(err error) error {
+	g.Message = err.Error()
+	return err
+}
+
+// Render draws the board, with the selected piece shown at the cursor
+// in lower case (or '*' where it collides), the pieces and the message
+func (g *Game) Render(w io.Writer) {
+	cells := g.Board.Clone().Cells
+	if _, ok := g.placement(g.Selected); !ok {
+		id := g.Pieces[g.Selected].ID
+		for _, p := range g.oriented[g.Selected].Cells() {
+			x, y := g.CursorX+p.X, g.CursorY+p.Y
+			if !g.Board.IsValidPosition(x, y) {
+				continue
+			}
+			if cells[y][x] == '.' {
+				cells[y][x] = unicode.ToLower(id)
+			} else {
+				cells[y][x] = '*'
+			}
+		}
+	} else if g.Board.IsValidPosition(g.CursorX, g.CursorY) && cells[g.CursorY][g.CursorX] == '.' {
+		cells[g.CursorY][g.CursorX] = '+'
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%dx%d board, %d of %d pieces placed\n\n", g.Board.Size, g.Board.Size, g.Placed(), len(g.Pieces))
+	for _, row := range cells {
+		b.WriteString(" ")
+		for _, cell := range row {
+			fmt.Fprintf(&b, " %c", cell)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\nPieces:")
+	for i, t := range g.Pieces {
+		label := string(t.ID)
+		if _, ok := g.placement(i); ok {
+			label = strings.ToLower(label)
+		}
+		if i == g.Selected {
+			label = "[" + label + "]"
+		}
+		b.WriteString(" " + label)
+	}
+	b.WriteString("  (lower case: placed)\n")
+
+	b.WriteString("arrows/hjkl move, r rotate, space place, x remove, tab/n next, p previous, A-Z select, ? hint, q quit\n")
+	if g.Message != "" {
+		b.WriteString("\n" + g.Message + "\n")
+	}
+
+	io.WriteString(w, b.String())
+}
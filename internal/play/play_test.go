@@ -0,0 +1,181 @@
+package play_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stkisengese/tetris-optimizer/internal/parser"
+	"github.com/stkisengese/tetris-optimizer/internal/play"
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+)
+
+// newGame starts a game from compact input
+func newGame(t *testing.T, input string, size int) *play.Game {
+	t.Helper()
+
+	pieces, err := parser.ParseCompact(strings.NewReader(input), "input")
+	if err != nil {
+		t.Fatalf("Failed to parse %q: %v", input, err)
+	}
+	g, err := play.New(pieces, size, solver.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	return g
+}
+
+func TestPlaceAndRemove(t *testing.T) {
+	g := newGame(t, "O x2", 4)
+
+	if err := g.Place(); err != nil {
+		t.Fatalf("Expected A to fit, got %v", err)
+	}
+	if g.Selected != 1 {
+		t.Errorf("Expected B to be selected after placing A, got %d", g.Selected)
+	}
+
+	// B would overlap A
+	before := g.Board.String()
+	if err := g.Place(); err == nil {
+		t.Error("Expected an error placing B over A, got nil")
+	}
+	if g.Board.String() != before {
+		t.Errorf("Expected a failed placement to leave the board alone, got:\n%s", g.Board)
+	}
+
+	g.Move(5, 0)
+	if g.CursorX != 2 {
+		t.Errorf("Expected the cursor to stop at the edge, got x=%d", g.CursorX)
+	}
+	if err := g.Place(); err != nil || !g.Solved() {
+		t.Fatalf("Expected B to fit and solve the board, got %v", err)
+	}
+	if want := "AABB\nAABB\n....\n....\n"; g.Board.String() != want {
+		t.Errorf("Expected board\n%s, got\n%s", want, g.Board)
+	}
+
+	// The cursor is on B, so B comes off
+	if err := g.Remove(); err != nil || g.Solved() || g.Selected != 1 {
+		t.Fatalf("Expected B to be removed and selected, got %v", err)
+	}
+	if want := "AA..\nAA..\n....\n....\n"; g.Board.String() != want {
+		t.Errorf("Expected board\n%s, got\n%s", want, g.Board)
+	}
+	if err := g.Remove(); err == nil {
+		t.Error("Expected an error with nothing to remove, got nil")
+	}
+}
+
+func TestRotate(t *testing.T) {
+	g := newGame(t, "I", 4)
+
+	// A flat I cannot leave the first column
+	g.Move(3, 0)
+	if g.CursorX != 0 {
+		t.Fatalf("Expected the cursor to stay at x=0, got %d", g.CursorX)
+	}
+
+	g.Rotate()
+	g.Move(3, 0)
+	if err := g.Place(); err != nil {
+		t.Fatalf("Expected the upright I to fit, got %v", err)
+	}
+	if want := "...A\n...A\n...A\n...A\n"; g.Board.String() != want {
+		t.Errorf("Expected board\n%s, got\n%s", want, g.Board)
+	}
+}
+
+func TestPinnedPieces(t *testing.T) {
+	g := newGame(t, "O=2,2 O", 4)
+
+	if g.Placed() != 1 || g.Selected != 1 {
+		t.Fatalf("Expected the pinned A on the board and B selected, got %d placed", g.Placed())
+	}
+	g.Move(3, 3)
+	if err := g.Remove(); err == nil {
+		t.Error("Expected an error removing a pinned piece, got nil")
+	}
+	g.Select('A')
+	if err := g.Place(); err == nil {
+		t.Error("Expected an error moving a pinned piece, got nil")
+	}
+}
+
+func TestHint(t *testing.T) {
+	ctx := context.Background()
+	g := newGame(t, "O x4", 4)
+
+	if ok, err := g.Hint(ctx); err != nil || !ok {
+		t.Errorf("Expected an empty board to be completable, got %v (%v)", ok, err)
+	}
+
+	// An O in the middle of the top row leaves gaps no O can fill
+	g.Move(1, 0)
+	g.Place()
+	if ok, err := g.Hint(ctx); err != nil || ok {
+		t.Errorf("Expected the board to be a dead end, got %v (%v)", ok, err)
+	}
+	if !strings.Contains(g.Message, "cannot be completed") {
+		t.Errorf("Expected the hint in the message, got %q", g.Message)
+	}
+}
+
+func TestRun(t *testing.T) {
+	g := newGame(t, "O x4", 4)
+
+	// Place A, then B two to the right, C two down and D two to the left
+	keys := " \x1b[C\x1b[C \x1b[B\x1b[B \x1b[D\x1b[D "
+	var out bytes.Buffer
+	solved, err := play.Run(context.Background(), g, strings.NewReader(keys), &out)
+	if err != nil || !solved {
+		t.Fatalf("Expected the board to be solved, got %v (%v)", solved, err)
+	}
+	if want := "AABB\nAABB\nDDCC\nDDCC\n"; g.Board.String() != want {
+		t.Errorf("Expected board\n%s, got\n%s", want, g.Board)
+	}
+	if !strings.Contains(out.String(), "solved on a 4x4 board") {
+		t.Errorf("Expected the final screen to say solved, got:\n%s", out.String())
+	}
+}
+
+func TestRunQuits(t *testing.T) {
+	for _, keys := range []string{"lq ", "r?x", "\x03"} {
+		g := newGame(t, "O x4", 4)
+
+		var out bytes.Buffer
+		solved, err := play.Run(context.Background(), g, strings.NewReader(keys), &out)
+		if err != nil || solved {
+			t.Errorf("Expected %q to end the game unsolved, got %v (%v)", keys, solved, err)
+		}
+	}
+}
+
+func TestRender(t *testing.T) {
+	g := newGame(t, "O T", 4)
+	g.Place()
+	g.Move(1, 1)
+
+	var out bytes.Buffer
+	g.Render(&out)
+	want := "4x4 board, 1 of 2 pieces placed\n\n" +
+		"  A A . .\n" +
+		"  A * b b\n" +
+		"  . . b .\n" +
+		"  . . . .\n\n" +
+		"Pieces: a [B]  (lower case: placed)\n"
+	if !strings.HasPrefix(out.String(), want) {
+		t.Errorf("Expected screen to start with\n%s, got\n%s", want, out.String())
+	}
+}
+
+func TestNewErrors(t *testing.T) {
+	pieces, _ := parser.ParseCompact(strings.NewReader("O=3,3"), "input")
+	if _, err := play.New(pieces, 4, solver.Options{}); err == nil {
+		t.Error("Expected an error for a pin off the board, got nil")
+	}
+	if _, err := play.New(nil, 4, solver.Options{}); err == nil {
+		t.Error("Expected an error with no pieces, got nil")
+	}
+}
@@ -0,0 +1,122 @@
+package play
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// HintTimeout bounds the search behind a hint
+const HintTimeout = 10 * time.Second
+
+// clearScreen moves the cursor home and clears the terminal
+const clearScreen = "\x1b[H\x1b[2J"
+
+// Run redraws the game after every key read from in until the board is
+// solved, the user quits or in ends. It reports whether the board was
+// solved. in is expected to be a terminal in raw mode, so keys arrive
+// unbuffered and arrow keys as escape sequences.
+func Run(ctx context.Context, g *Game, in io.Reader, out io.Writer) (bool, error) {
+	keys := bufio.NewReader(in)
+	for {
+		draw(g, out)
+		if g.Solved() {
+			return true, nil
+		}
+
+		key, err := readKey(keys)
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+
+		// Errors from the game are shown as its message
+		switch key {
+		case "q", "\x03", "\x04":
+			return false, nil
+		case "up", "k":
+			g.Move(0, -1)
+		case "down", "j":
+			g.Move(0, 1)
+		case "left", "h":
+			g.Move(-1, 0)
+		case "right", "l":
+			g.Move(1, 0)
+		case "r":
+			g.Rotate()
+		case " ", "\r", "\n":
+			g.Place()
+		case "x", "\x7f", "\b":
+			g.Remove()
+		case "\t", "n":
+			g.Cycle(1)
+		case "p":
+			g.Cycle(-1)
+		case "?":
+			g.Message = "thinking..."
+			draw(g, out)
+			hint(ctx, g)
+		default:
+			if len(key) == 1 && key[0] >= 'A' && key[0] <= 'Z' {
+				g.Select(rune(key[0]))
+			}
+		}
+	}
+}
+
+// hint runs Game.Hint within HintTimeout
+func hint(ctx context.Context, g *Game) {
+	ctx, cancel := context.WithTimeout(ctx, HintTimeout)
+	defer cancel()
+
+	if _, err := g.Hint(ctx); errors.Is(err, context.DeadlineExceeded) {
+		g.Message = fmt.Sprintf("hint: no answer within %v", HintTimeout)
+	}
+}
+
+// draw clears the terminal and renders the game
+func draw(g *Game, out io.Writer) {
+	io.WriteString(out, clearScreen)
+	g.Render(out)
+}
+
+// readKey reads one key press: a character, or "up", "down", "left" or
+// "right" for the arrow keys. Other escape sequences are returned whole.
+func readKey(r *bufio.Reader) (string, error) {
+	c, err := r.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	if c != 0x1b {
+		return string(c), nil
+	}
+
+	// Arrow keys arrive as ESC [ A to ESC [ D
+	seq := []byte{c}
+	for len(seq) < 3 {
+		c, err := r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		seq = append(seq, c)
+		if len(seq) == 2 && c != '[' {
+			break
+		}
+	}
+	switch string(seq) {
+	case "\x1b[A":
+		return "up", nil
+	case "\x1b[B":
+		return "down", nil
+	case "\x1b[C":
+		return "right", nil
+	case "\x1b[D":
+		return "left", nil
+	}
+	return string(seq), nil
+}
@@ -0,0 +1,9 @@
+package play
+
+import "syscall"
+
+// Terminal attribute requests
+const (
+	ioctlGetTermios = syscall.TCGETS
+	ioctlSetTermios = syscall.TCSETS
+)
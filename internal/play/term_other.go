@@ -0,0 +1,13 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package play
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MakeRaw reports that raw terminal mode is not supported here
+func MakeRaw(fd int) (restore func() error, err error) {
+	return nil, fmt.Errorf("raw terminal mode: %w", errors.ErrUnsupported)
+}
@@ -0,0 +1,41 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package play
+
+import (
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+// MakeRaw puts the terminal on file descriptor fd into raw mode, so key
+// presses are read one at a time without echo, and returns a function
+// that restores the previous mode. Output processing is kept, so "\n"
+// still starts a new line.
+func MakeRaw(fd int) (restore func() error, err error) {
+	var saved syscall.Termios
+	if err := ioctl(fd, ioctlGetTermios, &saved); err != nil {
+		return nil, fmt.Errorf("not a terminal: %v", err)
+	}
+
+	raw := saved
+	raw.Iflag &^= syscall.IGNBRK | syscall.BRKINT | syscall.PARMRK | syscall.ISTRIP | syscall.INLCR | syscall.IGNCR | syscall.ICRNL | syscall.IXON
+	raw.Lflag &^= syscall.ECHO | syscall.ECHONL | syscall.ICANON | syscall.ISIG | syscall.IEXTEN
+	raw.Cflag &^= syscall.CSIZE | syscall.PARENB
+	raw.Cflag |= syscall.CS8
+	raw.Cc[syscall.VMIN] = 1
+	raw.Cc[syscall.VTIME] = 0
+	if err := ioctl(fd, ioctlSetTermios, &raw); err != nil {
+		return nil, fmt.Errorf("cannot enter raw mode: %v", err)
+	}
+
+	return func() error { return ioctl(fd, ioctlSetTermios, &saved) }, nil
+}
+
+// ioctl gets or sets terminal attributes
+func ioctl(fd int, request uintptr, t *syscall.Termios) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), request, uintptr(unsafe.Pointer(t))); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// Piece describes one input piece in a solution document
+type Piece struct {
+	ID   string         `json:"id"`
+	Kind tetromino.Kind `json:"kind"`
+}
+
+// Stats summarises the pieces and the board of a solution
+type Stats struct {
+	Pieces     int                    `json:"pieces"`
+	Kinds      map[tetromino.Kind]int `json:"kinds"`
+	EmptyCells int                    `json:"empty_cells"`
+}
+
+// RuledOut is a board size a lower bound proved too small
+type RuledOut struct {
+	Size   int    `json:"size"`
+	Bound  string `json:"bound"`
+	Reason string `json:"reason"`
+}
+
+// Solution is the JSON document describing a solved puzzle
+type Solution struct {
+	Size     int        `json:"size"`
+	Grid     []string   `json:"grid"`
+	Pieces   []Piece    `json:"pieces"`
+	Stats    Stats      `json:"stats"`
+	RuledOut []RuledOut `json:"ruled_out,omitempty"`
+
+	// Optimal is false when the size is the best a heuristic found
+	// rather than a proven minimum
+	Optimal bool `json:"optimal"`
+}
+
+// NewSolution builds the document for a successful solve
+func NewSolution(tetrominoes []*tetromino.Tetromino, result *solver.Result) Solution {
+	doc := Solution{
+		Size:    result.Size,
+		Grid:    strings.Split(strings.TrimSuffix(result.Grid.String(), "\n"), "\n"),
+		Pieces:  make([]Piece, len(tetrominoes)),
+		Stats:   NewStats(tetrominoes, result),
+		Optimal: result.Optimal,
+	}
+
+	for i, t := range tetrominoes {
+		doc.Pieces[i] = Piece{ID: string(t.ID), Kind: t.Kind}
+	}
+
+	for _, proof := range result.Infeasible {
+		doc.RuledOut = append(doc.RuledOut, RuledOut{Size: proof.Size, Bound: proof.Bound, Reason: proof.Reason})
+	}
+
+	return doc
+}
+
+// NewStats computes statistics for a successful solve
+func NewStats(tetrominoes []*tetromino.Tetromino, result *solver.Result) Stats {
+	empty := 0
+	for y := 0; y < result.Size; y++ {
+		for x := 0; x < result.Size; x++ {
+			if result.Grid.IsEmpty(x, y) {
+				empty++
+			}
+		}
+	}
+
+	return Stats{
+		Pieces:     len(tetrominoes),
+		Kinds:      tetromino.CountKinds(tetrominoes),
+		EmptyCells: empty,
+	}
+}
+
+// FormatStats renders statistics as text lines
+func FormatStats(s Stats) string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "pieces: %d\n", s.Pieces)
+	builder.WriteString("kinds:")
+	for _, kind := range tetromino.Kinds {
+		if n := s.Kinds[kind]; n > 0 {
+			fmt.Fprintf(&builder, " %v=%d", kind, n)
+		}
+	}
+	if n := s.Kinds[tetromino.KindUnknown]; n > 0 {
+		fmt.Fprintf(&builder, " %v=%d", tetromino.KindUnknown, n)
+	}
+	builder.WriteString("\n")
+	fmt.Fprintf(&builder, "empty cells: %d\n", s.EmptyCells)
+
+	return builder.String()
+}
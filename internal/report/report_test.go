@@ -0,0 +1,43 @@
+package report_test
+
+import (
+	"testing"
+
+	"github.com/stkisengese/tetris-optimizer/internal/report"
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+func TestNewSolution(t *testing.T) {
+	square, _ := tetromino.NewStandard('A', tetromino.KindO)
+	line, _ := tetromino.NewStandard('B', tetromino.KindI)
+	pieces := []*tetromino.Tetromino{square, line}
+
+	result, err := solver.SolveOptimal(pieces)
+	if err != nil || !result.Success {
+		t.Fatalf("SolveOptimal() failed: %v", err)
+	}
+
+	doc := report.NewSolution(pieces, result)
+
+	if doc.Size != 4 || len(doc.Grid) != 4 {
+		t.Errorf("Expected a 4x4 grid, got size %d with %d rows", doc.Size, len(doc.Grid))
+	}
+
+	if len(doc.Pieces) != 2 || doc.Pieces[0].Kind != tetromino.KindO || doc.Pieces[1].ID != "B" {
+		t.Errorf("Unexpected pieces %+v", doc.Pieces)
+	}
+
+	if len(doc.RuledOut) != 1 || doc.RuledOut[0].Size != 3 || doc.RuledOut[0].Bound != solver.BoundPlacement {
+		t.Errorf("Expected size 3 ruled out by the placement bound, got %+v", doc.RuledOut)
+	}
+
+	if doc.Stats.EmptyCells != 8 {
+		t.Errorf("Expected 8 empty cells, got %d", doc.Stats.EmptyCells)
+	}
+
+	expected := "pieces: 2\nkinds: I=1 O=1\nempty cells: 8\n"
+	if text := report.FormatStats(doc.Stats); text != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, text)
+	}
+}
@@ -0,0 +1,181 @@
+// Package sat is a small, dependency-free SAT toolkit: a CNF formula
+// builder with DIMACS import and export, and a CDCL solver.
+//
+// Variables are numbered from 1. A literal is a variable number, negated
+// for the variable's negation, as in DIMACS.
+package sat
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// CNF is a formula in conjunctive normal form
+type CNF struct {
+	NumVars int
+	Clauses [][]int
+}
+
+// NewVar allocates a fresh variable and returns its number
+func (c *CNF) NewVar() int {
+	c.NumVars++
+	return c.NumVars
+}
+
+// AddClause adds the disjunction of lits
+func (c *CNF) AddClause(lits ...int) {
+	clause := make([]int, len(lits))
+	copy(clause, lits)
+	c.Clauses = append(c.Clauses, clause)
+}
+
+// pairwiseLimit is the largest group encoded with one clause per pair;
+// larger groups use the sequential counter, which needs linearly many
+// clauses
+const pairwiseLimit = 5
+
+// AtMostOne constrains at most one of lits to be true
+func (c *CNF) AtMostOne(lits ...int) {
+	if len(lits) <= pairwiseLimit {
+		for i := range lits {
+			for j := i + 1; j < len(lits); j++ {
+				c.AddClause(-lits[i], -lits[j])
+			}
+		}
+		return
+	}
+
+	// Sequential counter: s[i] is true once any of lits[0..i] is true
+	n := len(lits)
+	s := make([]int, n-1)
+	for i := range s {
+		s[i] = c.NewVar()
+	}
+
+	c.AddClause(-lits[0], s[0])
+	for i := 1; i < n-1; i++ {
+		c.AddClause(-lits[i], s[i])
+		c.AddClause(-s[i-1], s[i])
+		c.AddClause(-lits[i], -s[i-1])
+	}
+	c.AddClause(-lits[n-1], -s[n-2])
+}
+
+// ExactlyOne constrains exactly one of lits to be true
+func (c *CNF) ExactlyOne(lits ...int) {
+	c.AddClause(lits...)
+	c.AtMostOne(lits...)
+}
+
+// Satisfied reports whether model, indexed by variable number, satisfies
+// every clause
+func (c *CNF) Satisfied(model []bool) bool {
+	for _, clause := range c.Clauses {
+		ok := false
+		for _, lit := range clause {
+			if v := abs(lit); v < len(model) && model[v] == (lit > 0) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// WriteDIMACS writes the formula in DIMACS CNF format. Each comment is
+// written as a "c" line before the header.
+func (c *CNF) WriteDIMACS(w io.Writer, comments ...string) error {
+	bw := bufio.NewWriter(w)
+
+	for _, comment := range comments {
+		fmt.Fprintf(bw, "c %s\n", comment)
+	}
+	fmt.Fprintf(bw, "p cnf %d %d\n", c.NumVars, len(c.Clauses))
+
+	for _, clause := range c.Clauses {
+		for _, lit := range clause {
+			bw.WriteString(strconv.Itoa(lit))
+			bw.WriteByte(' ')
+		}
+		bw.WriteString("0\n")
+	}
+
+	return bw.Flush()
+}
+
+// ParseDIMACS reads a formula in DIMACS CNF format
+func ParseDIMACS(r io.Reader) (*CNF, error) {
+	scanner := bufio.NewScanner(r)
+	cnf := &CNF{}
+	header := false
+	var clause []int
+	lineNum := 0
+
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "c") || strings.HasPrefix(line, "%") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "p") {
+			fields := strings.Fields(line)
+			if header || len(fields) != 4 || fields[1] != "cnf" {
+				return nil, fmt.Errorf("line %d: invalid header %q", lineNum, line)
+			}
+			n, err := strconv.Atoi(fields[2])
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("line %d: invalid variable count %q", lineNum, fields[2])
+			}
+			cnf.NumVars = n
+			header = true
+			continue
+		}
+
+		if !header {
+			return nil, fmt.Errorf("line %d: clause before header", lineNum)
+		}
+
+		for _, field := range strings.Fields(line) {
+			lit, err := strconv.Atoi(field)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid literal %q", lineNum, field)
+			}
+			if abs(lit) > cnf.NumVars {
+				return nil, fmt.Errorf("line %d: literal %d exceeds %d variables", lineNum, lit, cnf.NumVars)
+			}
+			if lit == 0 {
+				cnf.Clauses = append(cnf.Clauses, clause)
+				clause = nil
+				continue
+			}
+			clause = append(clause, lit)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !header {
+		return nil, fmt.Errorf("missing \"p cnf\" header")
+	}
+	if len(clause) > 0 {
+		cnf.Clauses = append(cnf.Clauses, clause)
+	}
+
+	return cnf, nil
+}
+
+// abs returns the variable number of a literal
+func abs(lit int) int {
+	if lit < 0 {
+		return -lit
+	}
+	return lit
+}
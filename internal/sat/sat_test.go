@@ -0,0 +1,207 @@
+package sat
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// pigeonhole encodes placing n+1 pigeons into n holes, which is
+// unsatisfiable
+func pigeonhole(n int) *CNF {
+	cnf := &CNF{}
+	vars := make([][]int, n+1)
+	for p := range vars {
+		vars[p] = make([]int, n)
+		for h := range vars[p] {
+			vars[p][h] = cnf.NewVar()
+		}
+		cnf.AddClause(vars[p]...)
+	}
+	for h := 0; h < n; h++ {
+		column := make([]int, n+1)
+		for p := range vars {
+			column[p] = vars[p][h]
+		}
+		cnf.AtMostOne(column...)
+	}
+	return cnf
+}
+
+func TestLuby(t *testing.T) {
+	expected := []int{1, 1, 2, 1, 1, 2, 4, 1, 1, 2, 1, 1, 2, 4, 8}
+	for i, want := range expected {
+		if got := luby(i + 1); got != want {
+			t.Errorf("luby(%d) = %d, expected %d", i+1, got, want)
+		}
+	}
+}
+
+func TestSolveSmall(t *testing.T) {
+	testCases := []struct {
+		name    string
+		clauses [][]int
+		numVars int
+		sat     bool
+	}{
+		{name: "empty", numVars: 0, sat: true},
+		{name: "unit", numVars: 1, clauses: [][]int{{1}}, sat: true},
+		{name: "contradiction", numVars: 1, clauses: [][]int{{1}, {-1}}, sat: false},
+		{name: "empty clause", numVars: 1, clauses: [][]int{{}}, sat: false},
+		{name: "chain", numVars: 3, clauses: [][]int{{1}, {-1, 2}, {-2, 3}, {-3, -1, 2}}, sat: true},
+		{name: "all four", numVars: 2, clauses: [][]int{{1, 2}, {1, -2}, {-1, 2}, {-1, -2}}, sat: false},
+		{name: "tautology", numVars: 1, clauses: [][]int{{1, -1}}, sat: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cnf := &CNF{NumVars: tc.numVars, Clauses: tc.clauses}
+			s := NewSolverCNF(cnf)
+			ok, err := s.Solve(context.Background())
+			if err != nil {
+				t.Fatalf("Solve() error = %v", err)
+			}
+			if ok != tc.sat {
+				t.Fatalf("Expected sat=%v, got %v", tc.sat, ok)
+			}
+			if ok && !cnf.Satisfied(s.Model()) {
+				t.Errorf("Expected model to satisfy the formula")
+			}
+		})
+	}
+}
+
+func TestSolvePigeonhole(t *testing.T) {
+	for n := 2; n <= 6; n++ {
+		ok, err := NewSolverCNF(pigeonhole(n)).Solve(context.Background())
+		if err != nil {
+			t.Fatalf("Solve() error = %v", err)
+		}
+		if ok {
+			t.Errorf("Expected %d pigeons in %d holes to be unsatisfiable", n+1, n)
+		}
+	}
+}
+
+// TestSolveRandom checks random 3-SAT instances around the phase
+// transition against brute force
+func TestSolveRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	for round := 0; round < 200; round++ {
+		numVars := 4 + rng.Intn(9)
+		cnf := &CNF{NumVars: numVars}
+		for i := 0; i < numVars*43/10; i++ {
+			clause := make([]int, 3)
+			for j := range clause {
+				clause[j] = 1 + rng.Intn(numVars)
+				if rng.Intn(2) == 0 {
+					clause[j] = -clause[j]
+				}
+			}
+			cnf.AddClause(clause...)
+		}
+
+		s := NewSolverCNF(cnf)
+		ok, err := s.Solve(context.Background())
+		if err != nil {
+			t.Fatalf("Solve() error = %v", err)
+		}
+
+		if want := bruteForce(cnf); ok != want {
+			t.Fatalf("Round %d: expected sat=%v, got %v", round, want, ok)
+		}
+		if ok && !cnf.Satisfied(s.Model()) {
+			t.Fatalf("Round %d: model does not satisfy the formula", round)
+		}
+	}
+}
+
+// bruteForce decides satisfiability by trying every assignment
+func bruteForce(cnf *CNF) bool {
+	model := make([]bool, cnf.NumVars+1)
+	for bits := 0; bits < 1<<cnf.NumVars; bits++ {
+		for v := 1; v <= cnf.NumVars; v++ {
+			model[v] = bits&(1<<(v-1)) != 0
+		}
+		if cnf.Satisfied(model) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestExactlyOne(t *testing.T) {
+	for _, n := range []int{1, 3, 8} {
+		cnf := &CNF{}
+		lits := make([]int, n)
+		for i := range lits {
+			lits[i] = cnf.NewVar()
+		}
+		cnf.ExactlyOne(lits...)
+
+		// Forcing two of them true must fail
+		if n > 1 {
+			forced := &CNF{NumVars: cnf.NumVars, Clauses: append(cnf.Clauses, []int{lits[0]}, []int{lits[n-1]})}
+			if ok, _ := NewSolverCNF(forced).Solve(context.Background()); ok {
+				t.Errorf("Expected two of %d true to be unsatisfiable", n)
+			}
+		}
+
+		s := NewSolverCNF(cnf)
+		if ok, _ := s.Solve(context.Background()); !ok {
+			t.Fatalf("Expected exactly one of %d to be satisfiable", n)
+		}
+		count := 0
+		for _, l := range lits {
+			if s.Value(l) {
+				count++
+			}
+		}
+		if count != 1 {
+			t.Errorf("Expected exactly one true literal, got %d", count)
+		}
+	}
+}
+
+func TestSolveCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := NewSolverCNF(pigeonhole(9)).Solve(ctx); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestDIMACSRoundTrip(t *testing.T) {
+	cnf := pigeonhole(3)
+
+	var buf bytes.Buffer
+	if err := cnf.WriteDIMACS(&buf, "pigeonhole 4 into 3"); err != nil {
+		t.Fatalf("WriteDIMACS() error = %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "c pigeonhole 4 into 3\np cnf ") {
+		t.Errorf("Expected comment and header, got %q", buf.String()[:40])
+	}
+
+	parsed, err := ParseDIMACS(&buf)
+	if err != nil {
+		t.Fatalf("ParseDIMACS() error = %v", err)
+	}
+	if parsed.NumVars != cnf.NumVars || len(parsed.Clauses) != len(cnf.Clauses) {
+		t.Fatalf("Expected %d vars and %d clauses, got %d and %d", cnf.NumVars, len(cnf.Clauses), parsed.NumVars, len(parsed.Clauses))
+	}
+	for i := range cnf.Clauses {
+		if len(parsed.Clauses[i]) != len(cnf.Clauses[i]) {
+			t.Fatalf("Clause %d differs: %v vs %v", i, parsed.Clauses[i], cnf.Clauses[i])
+		}
+	}
+
+	for _, bad := range []string{"1 2 0\n", "p cnf 1 1\n2 0\n", "p dnf 1 1\n", "p cnf 1 1\nx 0\n"} {
+		if _, err := ParseDIMACS(strings.NewReader(bad)); err == nil {
+			t.Errorf("Expected error for %q, got nil", bad)
+		}
+	}
+}
@@ -0,0 +1,563 @@
+package sat
+
+import (
+	"context"
+)
+
+// Stats counts the work done by a solve
+type Stats struct {
+	Decisions    int
+	Conflicts    int
+	Propagations int
+}
+
+// lit is the solver's internal literal: variable v (0-based) is 2v and
+// its negation 2v+1
+type lit int32
+
+// value of a literal or variable under the current assignment
+const (
+	unassigned int8 = 0
+	valTrue    int8 = 1
+	valFalse   int8 = -1
+)
+
+// noReason marks decisions and unit clauses
+const noReason = -1
+
+// Tuning constants
+const (
+	varDecay      = 0.95
+	restartBase   = 100
+	rescaleLimit  = 1e100
+	cancelEvery   = 256
+	rescaleFactor = 1e-100
+)
+
+// Solver is a conflict-driven clause learning SAT solver with two
+// watched literals, first-UIP learning, activity-based branching with
+// phase saving, and Luby restarts
+type Solver struct {
+	numVars int
+	clauses [][]lit
+	watches [][]int
+
+	assigns  []int8
+	level    []int
+	reason   []int
+	polarity []bool
+	trail    []lit
+	trailLim []int
+	qhead    int
+
+	activity []float64
+	varInc   float64
+	heap     varHeap
+	seen     []bool
+
+	unsat bool
+	stats Stats
+}
+
+// NewSolver creates a solver over variables 1..numVars
+func NewSolver(numVars int) *Solver {
+	s := &Solver{
+		numVars:  numVars,
+		watches:  make([][]int, 2*numVars),
+		assigns:  make([]int8, numVars),
+		level:    make([]int, numVars),
+		reason:   make([]int, numVars),
+		polarity: make([]bool, numVars),
+		activity: make([]float64, numVars),
+		varInc:   1,
+		seen:     make([]bool, numVars),
+	}
+	s.heap = varHeap{activity: s.activity, index: make([]int, numVars)}
+	for v := 0; v < numVars; v++ {
+		s.heap.index[v] = -1
+		s.heap.push(v)
+	}
+	return s
+}
+
+// NewSolverCNF creates a solver loaded with every clause of a formula
+func NewSolverCNF(cnf *CNF) *Solver {
+	s := NewSolver(cnf.NumVars)
+	for _, clause := range cnf.Clauses {
+		s.AddClause(clause...)
+	}
+	return s
+}
+
+// AddClause adds a clause in DIMACS literals. Clauses must be added
+// before Solve is called.
+func (s *Solver) AddClause(lits ...int) {
+	if s.unsat {
+		return
+	}
+
+	clause := make([]lit, 0, len(lits))
+	for _, l := range lits {
+		internal := toLit(l)
+		switch s.value(internal) {
+		case valTrue:
+			// Already satisfied at level 0
+			return
+		case valFalse:
+			continue
+		}
+
+		duplicate := false
+		for _, existing := range clause {
+			if existing == internal {
+				duplicate = true
+			}
+			if existing == internal^1 {
+				// Tautology
+				return
+			}
+		}
+		if !duplicate {
+			clause = append(clause, internal)
+		}
+	}
+
+	switch len(clause) {
+	case 0:
+		s.unsat = true
+	case 1:
+		s.enqueue(clause[0], noReason)
+		if s.propagate() != noReason {
+			s.unsat = true
+		}
+	default:
+		s.attach(clause)
+	}
+}
+
+// Solve searches for a satisfying assignment. It returns false when the
+// clauses are unsatisfiable, and ctx.Err() if ctx is done first.
+func (s *Solver) Solve(ctx context.Context) (bool, error) {
+	if s.unsat {
+		return false, nil
+	}
+
+	for restart := 1; ; restart++ {
+		status, err := s.search(ctx, restartBase*luby(restart))
+		if err != nil {
+			return false, err
+		}
+		if status != unassigned {
+			return status == valTrue, nil
+		}
+	}
+}
+
+// Value returns the assignment of variable v (1-based) in the model
+// found by Solve
+func (s *Solver) Value(v int) bool {
+	return s.assigns[v-1] == valTrue
+}
+
+// Model returns the assignment indexed by variable number; index 0 is
+// unused
+func (s *Solver) Model() []bool {
+	model := make([]bool, s.numVars+1)
+	for v := 1; v <= s.numVars; v++ {
+		model[v] = s.Value(v)
+	}
+	return model
+}
+
+// Stats returns the work counters of the last Solve
+func (s *Solver) Stats() Stats {
+	return s.stats
+}
+
+// search runs CDCL until it finds a model (valTrue), proves the formula
+// unsatisfiable (valFalse) or reaches the conflict budget (unassigned)
+func (s *Solver) search(ctx context.Context, budget int) (int8, error) {
+	conflicts := 0
+
+	for {
+		if conflict := s.propagate(); conflict != noReason {
+			s.stats.Conflicts++
+			conflicts++
+			if s.stats.Conflicts%cancelEvery == 0 {
+				if err := ctx.Err(); err != nil {
+					return unassigned, err
+				}
+			}
+
+			if s.decisionLevel() == 0 {
+				s.unsat = true
+				return valFalse, nil
+			}
+
+			learnt, backtrackLevel := s.analyze(conflict)
+			s.cancelUntil(backtrackLevel)
+			if len(learnt) == 1 {
+				s.enqueue(learnt[0], noReason)
+			} else {
+				s.enqueue(learnt[0], s.attach(learnt))
+			}
+			s.varInc /= varDecay
+			continue
+		}
+
+		if conflicts >= budget {
+			s.cancelUntil(0)
+			return unassigned, nil
+		}
+
+		v := s.pickBranchVar()
+		if v < 0 {
+			return valTrue, nil
+		}
+
+		s.stats.Decisions++
+		if s.stats.Decisions%cancelEvery == 0 {
+			if err := ctx.Err(); err != nil {
+				return unassigned, err
+			}
+		}
+
+		s.trailLim = append(s.trailLim, len(s.trail))
+		l := lit(2 * v)
+		if !s.polarity[v] {
+			l ^= 1
+		}
+		s.enqueue(l, noReason)
+	}
+}
+
+// attach stores a clause of two or more literals and watches its first
+// two, returning its index
+func (s *Solver) attach(clause []lit) int {
+	index := len(s.clauses)
+	s.clauses = append(s.clauses, clause)
+	s.watches[clause[0]] = append(s.watches[clause[0]], index)
+	s.watches[clause[1]] = append(s.watches[clause[1]], index)
+	return index
+}
+
+// enqueue assigns a literal true
+func (s *Solver) enqueue(l lit, reason int) {
+	v := l.variable()
+	s.assigns[v] = valTrue
+	if l.negated() {
+		s.assigns[v] = valFalse
+	}
+	s.level[v] = s.decisionLevel()
+	s.reason[v] = reason
+	s.trail = append(s.trail, l)
+}
+
+// propagate applies unit propagation to every newly assigned literal and
+// returns the index of a conflicting clause, or noReason
+func (s *Solver) propagate() int {
+	for s.qhead < len(s.trail) {
+		falseLit := s.trail[s.qhead] ^ 1
+		s.qhead++
+		s.stats.Propagations++
+
+		watchers := s.watches[falseLit]
+		kept := watchers[:0]
+
+		for i, ci := range watchers {
+			clause := s.clauses[ci]
+
+			// Keep the false literal in position 1
+			if clause[0] == falseLit {
+				clause[0], clause[1] = clause[1], clause[0]
+			}
+
+			if s.value(clause[0]) == valTrue {
+				kept = append(kept, ci)
+				continue
+			}
+
+			moved := false
+			for k := 2; k < len(clause); k++ {
+				if s.value(clause[k]) != valFalse {
+					clause[1], clause[k] = clause[k], clause[1]
+					s.watches[clause[1]] = append(s.watches[clause[1]], ci)
+					moved = true
+					break
+				}
+			}
+			if moved {
+				continue
+			}
+
+			kept = append(kept, ci)
+			if s.value(clause[0]) == valFalse {
+				kept = append(kept, watchers[i+1:]...)
+				s.watches[falseLit] = kept
+				s.qhead = len(s.trail)
+				return ci
+			}
+			s.enqueue(clause[0], ci)
+		}
+
+		s.watches[falseLit] = kept
+	}
+
+	return noReason
+}
+
+// analyze derives the first-UIP learnt clause from a conflict. The
+// asserting literal is first and a literal of the backtrack level second.
+func (s *Solver) analyze(conflict int) ([]lit, int) {
+	learnt := []lit{0}
+	pathCount := 0
+	var p lit = -1
+	index := len(s.trail) - 1
+	ci := conflict
+
+	for {
+		clause := s.clauses[ci]
+		start := 0
+		if p >= 0 {
+			// clause[0] is p, the literal this clause implied
+			start = 1
+		}
+
+		for _, q := range clause[start:] {
+			v := q.variable()
+			if s.seen[v] || s.level[v] == 0 {
+				continue
+			}
+			s.seen[v] = true
+			s.bump(v)
+			if s.level[v] >= s.decisionLevel() {
+				pathCount++
+			} else {
+				learnt = append(learnt, q)
+			}
+		}
+
+		for !s.seen[s.trail[index].variable()] {
+			index--
+		}
+		p = s.trail[index]
+		index--
+		ci = s.reason[p.variable()]
+		s.seen[p.variable()] = false
+		pathCount--
+
+		if pathCount == 0 {
+			break
+		}
+	}
+	learnt[0] = p ^ 1
+
+	// Drop literals implied by the others: a literal whose reason only
+	// involves literals already in the clause is redundant
+	marked := append([]lit(nil), learnt...)
+	kept := 1
+	for _, q := range learnt[1:] {
+		if !s.redundant(q) {
+			learnt[kept] = q
+			kept++
+		}
+	}
+	learnt = learnt[:kept:kept]
+
+	backtrackLevel := 0
+	for i := 1; i < len(learnt); i++ {
+		if lvl := s.level[learnt[i].variable()]; lvl > backtrackLevel {
+			backtrackLevel = lvl
+			learnt[1], learnt[i] = learnt[i], learnt[1]
+		}
+	}
+
+	for _, l := range marked {
+		s.seen[l.variable()] = false
+	}
+
+	return learnt, backtrackLevel
+}
+
+// redundant reports whether every other literal of q's reason is in the
+// learnt clause being built or fixed at level 0
+func (s *Solver) redundant(q lit) bool {
+	ci := s.reason[q.variable()]
+	if ci == noReason {
+		return false
+	}
+
+	for _, r := range s.clauses[ci][1:] {
+		v := r.variable()
+		if !s.seen[v] && s.level[v] != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// cancelUntil undoes every assignment above the given decision level,
+// saving the phases of the unassigned variables
+func (s *Solver) cancelUntil(level int) {
+	if s.decisionLevel() <= level {
+		return
+	}
+
+	for i := len(s.trail) - 1; i >= s.trailLim[level]; i-- {
+		v := s.trail[i].variable()
+		s.polarity[v] = !s.trail[i].negated()
+		s.assigns[v] = unassigned
+		if !s.heap.contains(v) {
+			s.heap.push(v)
+		}
+	}
+
+	s.trail = s.trail[:s.trailLim[level]]
+	s.trailLim = s.trailLim[:level]
+	s.qhead = len(s.trail)
+}
+
+// pickBranchVar returns the unassigned variable with the highest
+// activity, or -1 when every variable is assigned
+func (s *Solver) pickBranchVar() int {
+	for s.heap.len() > 0 {
+		v := s.heap.pop()
+		if s.assigns[v] == unassigned {
+			return v
+		}
+	}
+	return -1
+}
+
+// bump raises a variable's activity after it took part in a conflict
+func (s *Solver) bump(v int) {
+	s.activity[v] += s.varInc
+	if s.activity[v] > rescaleLimit {
+		for i := range s.activity {
+			s.activity[i] *= rescaleFactor
+		}
+		s.varInc *= rescaleFactor
+	}
+	if s.heap.contains(v) {
+		s.heap.up(s.heap.index[v])
+	}
+}
+
+// decisionLevel is the number of decisions on the trail
+func (s *Solver) decisionLevel() int {
+	return len(s.trailLim)
+}
+
+// value returns the current value of a literal
+func (s *Solver) value(l lit) int8 {
+	val := s.assigns[l.variable()]
+	if l.negated() {
+		return -val
+	}
+	return val
+}
+
+// toLit converts a DIMACS literal to the internal form
+func toLit(l int) lit {
+	if l < 0 {
+		return lit(2*(-l-1) + 1)
+	}
+	return lit(2 * (l - 1))
+}
+
+// variable returns the 0-based variable of a literal
+func (l lit) variable() int {
+	return int(l >> 1)
+}
+
+// negated reports whether the literal is a negation
+func (l lit) negated() bool {
+	return l&1 == 1
+}
+
+// luby returns the i-th element (1-based) of the Luby restart sequence
+// 1, 1, 2, 1, 1, 2, 4, ...
+func luby(i int) int {
+	x := i - 1
+	size, seq := 1, 0
+	for size < x+1 {
+		seq++
+		size = 2*size + 1
+	}
+	for size-1 != x {
+		size = (size - 1) >> 1
+		seq--
+		x %= size
+	}
+	return 1 << seq
+}
+
+// varHeap is a binary max-heap of variables ordered by activity
+type varHeap struct {
+	activity []float64
+	heap     []int
+	index    []int
+}
+
+func (h *varHeap) len() int            { return len(h.heap) }
+func (h *varHeap) contains(v int) bool { return h.index[v] >= 0 }
+
+// push adds a variable
+func (h *varHeap) push(v int) {
+	h.index[v] = len(h.heap)
+	h.heap = append(h.heap, v)
+	h.up(h.index[v])
+}
+
+// pop removes and returns the most active variable
+func (h *varHeap) pop() int {
+	top := h.heap[0]
+	last := h.heap[len(h.heap)-1]
+	h.heap = h.heap[:len(h.heap)-1]
+	h.index[top] = -1
+
+	if len(h.heap) > 0 {
+		h.heap[0] = last
+		h.index[last] = 0
+		h.down(0)
+	}
+	return top
+}
+
+// up moves the variable at position i towards the root
+func (h *varHeap) up(i int) {
+	v := h.heap[i]
+	for i > 0 {
+		parent := (i - 1) / 2
+		if h.activity[h.heap[parent]] >= h.activity[v] {
+			break
+		}
+		h.heap[i] = h.heap[parent]
+		h.index[h.heap[i]] = i
+		i = parent
+	}
+	h.heap[i] = v
+	h.index[v] = i
+}
+
+// down moves the variable at position i towards the leaves
+func (h *varHeap) down(i int) {
+	v := h.heap[i]
+	for {
+		child := 2*i + 1
+		if child >= len(h.heap) {
+			break
+		}
+		if right := child + 1; right < len(h.heap) && h.activity[h.heap[right]] > h.activity[h.heap[child]] {
+			child = right
+		}
+		if h.activity[h.heap[child]] <= h.activity[v] {
+			break
+		}
+		h.heap[i] = h.heap[child]
+		h.index[h.heap[i]] = i
+		i = child
+	}
+	h.heap[i] = v
+	h.index[v] = i
+}
@@ -0,0 +1,94 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/stkisengese/tetris-optimizer/internal/jobs"
+)
+
+// jobResponse describes a job without its input or result
+type jobResponse struct {
+	ID      string      `json:"id"`
+	Status  jobs.Status `json:"status"`
+	Created time.Time   `json:"created"`
+	Updated time.Time   `json:"updated"`
+	Error   string      `json:"error,omitempty"`
+}
+
+// newJobResponse summarises a job for the status endpoints
+func newJobResponse(job jobs.Job) jobResponse {
+	return jobResponse{ID: job.ID, Status: job.Status, Created: job.Created, Updated: job.Updated, Error: job.Error}
+}
+
+// handleJobSubmit queues a puzzle and returns its job ID
+func (s *Server) handleJobSubmit(w http.ResponseWriter, r *http.Request) {
+	data, err := s.readBody(w, r)
+	if err != nil {
+		writeBodyError(w, err)
+		return
+	}
+
+	if _, err := s.parsePuzzle(data, requestFormat(r)); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	job, err := s.cfg.Jobs.Submit(string(data), requestFormat(r))
+	if err != nil {
+		if errors.Is(err, jobs.ErrClosed) {
+			writeError(w, http.StatusServiceUnavailable, err)
+			return
+		}
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	w.Header().Set("Location", "/jobs/"+job.ID)
+	writeJSON(w, http.StatusAccepted, newJobResponse(job))
+}
+
+// handleJobStatus reports the state of a job
+func (s *Server) handleJobStatus(w http.ResponseWriter, r *http.Request) {
+	job, err := s.cfg.Jobs.Get(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newJobResponse(job))
+}
+
+// handleJobResult returns the solution of a finished job
+func (s *Server) handleJobResult(w http.ResponseWriter, r *http.Request) {
+	job, err := s.cfg.Jobs.Get(r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+
+	switch job.Status {
+	case jobs.StatusDone:
+		writeJSON(w, http.StatusOK, job.Result)
+	case jobs.StatusFailed, jobs.StatusCancelled:
+		writeError(w, http.StatusUnprocessableEntity, fmt.Errorf("job %s: %s", job.Status, job.Error))
+	default:
+		writeError(w, http.StatusConflict, fmt.Errorf("job is %s", job.Status))
+	}
+}
+
+// handleJobCancel cancels a queued or running job
+func (s *Server) handleJobCancel(w http.ResponseWriter, r *http.Request) {
+	job, err := s.cfg.Jobs.Cancel(r.PathValue("id"))
+	switch {
+	case errors.Is(err, jobs.ErrNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, jobs.ErrFinished):
+		writeError(w, http.StatusConflict, err)
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+	default:
+		writeJSON(w, http.StatusAccepted, newJobResponse(job))
+	}
+}
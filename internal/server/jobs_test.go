@@ -0,0 +1,86 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stkisengese/tetris-optimizer/internal/jobs"
+	"github.com/stkisengese/tetris-optimizer/internal/server"
+)
+
+// get sends a GET request to the server and returns the recorded response
+func get(srv *server.Server, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+	return rec
+}
+
+func TestJobs(t *testing.T) {
+	store, err := jobs.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error creating store, got %v", err)
+	}
+	queue, err := jobs.NewQueue(store, jobs.Config{})
+	if err != nil {
+		t.Fatalf("Expected no error creating queue, got %v", err)
+	}
+	defer queue.Close()
+
+	srv := server.New(server.Config{Jobs: queue})
+
+	rec := post(t, srv, "/jobs", "text/plain", "O x4")
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var job struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &job); err != nil || job.ID == "" {
+		t.Fatalf("Expected job ID in response, got %s", rec.Body.String())
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for job.Status != string(jobs.StatusDone) {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected job to finish, still %s", job.Status)
+		}
+		time.Sleep(5 * time.Millisecond)
+		json.Unmarshal(get(srv, "/jobs/"+job.ID).Body.Bytes(), &job)
+	}
+
+	rec = get(srv, "/jobs/"+job.ID+"/result")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	req := httptest.NewRequest(http.MethodDelete, "/jobs/"+job.ID, nil)
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	if rec.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 cancelling a finished job, got %d", rec.Code)
+	}
+
+	if rec := get(srv, "/jobs/missing"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", rec.Code)
+	}
+	if rec := post(t, srv, "/jobs", "", "Q"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+	if rec := post(t, srv, "/jobs", "application/json", `{"pieces": [{"kind": "I"}], "board": {"size": 100000}}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an oversized board, got %d", rec.Code)
+	}
+	if rec := post(t, srv, "/jobs", "text/plain", "I x50000000"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for too many pieces, got %d", rec.Code)
+	}
+}
+
+func TestJobsDisabled(t *testing.T) {
+	if rec := get(server.New(server.Config{}), "/jobs/abc"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 without a job queue, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,312 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/stkisengese/tetris-optimizer/internal/cache"
+	"github.com/stkisengese/tetris-optimizer/internal/grid"
+	"github.com/stkisengese/tetris-optimizer/internal/jobs"
+	"github.com/stkisengese/tetris-optimizer/internal/parser"
+	"github.com/stkisengese/tetris-optimizer/internal/report"
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// Config controls the limits applied to every request
+type Config struct {
+	// MaxBodyBytes caps the size of a request body
+	MaxBodyBytes int64
+
+	// MaxBoardSize caps the side of any board a request may search,
+	// whether set by the size, maximum size, mask, pinned pieces or the
+	// number of pieces
+	MaxBoardSize int
+
+	// Timeout bounds how long a single solve may run
+	Timeout time.Duration
+
+	// MaxSolvers caps how many solves run at once; further requests wait
+	// for a free slot until their timeout expires
+	MaxSolvers int
+
+	// ProgressEvery sets how many search nodes pass between progress
+	// events on the streaming endpoint. Zero uses the solver default.
+	ProgressEvery int
+
+	// Cache, when set, answers repeated puzzles without searching
+	Cache *cache.Cache
+
+	// Jobs, when set, enables the asynchronous /jobs endpoints
+	Jobs *jobs.Queue
+}
+
+// Default limits used for zero Config fields
+const (
+	DefaultMaxBodyBytes = 1 << 20
+	DefaultMaxBoardSize = 64
+	DefaultTimeout      = 30 * time.Second
+)
+
+// errBusy reports that no solver slot became free before the deadline
+var errBusy = errors.New("server busy: no solver available")
+
+// Server serves the optimizer over HTTP
+type Server struct {
+	cfg   Config
+	mux   *http.ServeMux
+	slots chan struct{}
+}
+
+// New creates a server, filling in defaults for zero config fields
+func New(cfg Config) *Server {
+	if cfg.MaxBodyBytes <= 0 {
+		cfg.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	if cfg.MaxBoardSize <= 0 {
+		cfg.MaxBoardSize = DefaultMaxBoardSize
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = DefaultTimeout
+	}
+	if cfg.MaxSolvers <= 0 {
+		cfg.MaxSolvers = runtime.NumCPU()
+	}
+
+	s := &Server{
+		cfg:   cfg,
+		mux:   http.NewServeMux(),
+		slots: make(chan struct{}, cfg.MaxSolvers),
+	}
+
+	s.mux.HandleFunc("POST /solve", s.handleSolve)
+	s.mux.HandleFunc("POST /solve/stream", s.handleSolveStream)
+	s.mux.HandleFunc("POST /verify", s.handleVerify)
+	s.mux.HandleFunc("GET /healthz", s.handleHealth)
+
+	if cfg.Jobs != nil {
+		s.mux.HandleFunc("POST /jobs", s.handleJobSubmit)
+		s.mux.HandleFunc("GET /jobs/{id}", s.handleJobStatus)
+		s.mux.HandleFunc("GET /jobs/{id}/result", s.handleJobResult)
+		s.mux.HandleFunc("DELETE /jobs/{id}", s.handleJobCancel)
+	}
+
+	return s
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// verifyRequest is the body of POST /verify. Input holds the puzzle,
+// either as a string in any text format or as a JSON input document.
+type verifyRequest struct {
+	Input json.RawMessage `json:"input"`
+	Grid  []string        `json:"grid"`
+}
+
+// verifyResponse reports whether a proposed grid solves the puzzle
+type verifyResponse struct {
+	Valid bool   `json:"valid"`
+	Size  int    `json:"size"`
+	Error string `json:"error,omitempty"`
+}
+
+// errorResponse is the body of every failed request
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// handleSolve solves a puzzle sent as raw text or JSON
+func (s *Server) handleSolve(w http.ResponseWriter, r *http.Request) {
+	puzzle, ok := s.readPuzzle(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := s.solve(r.Context(), puzzle.Pieces, puzzle.Options)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+
+	if !result.Success {
+		writeError(w, http.StatusUnprocessableEntity, errors.New("no solution found"))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, report.NewSolution(puzzle.Pieces, result))
+}
+
+// handleVerify checks a proposed grid against a puzzle
+func (s *Server) handleVerify(w http.ResponseWriter, r *http.Request) {
+	data, err := s.readBody(w, r)
+	if err != nil {
+		writeBodyError(w, err)
+		return
+	}
+
+	var req verifyRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %v", err))
+		return
+	}
+
+	puzzle, err := parseVerifyInput(req.Input)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	g, err := grid.FromRows(req.Grid)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	resp := verifyResponse{Valid: true, Size: g.Size}
+	if err := solver.Verify(puzzle.Pieces, g); err != nil {
+		resp.Valid = false
+		resp.Error = err.Error()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleHealth reports that the server is up
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// readPuzzle reads and parses the puzzle in a solve request body. On
+// failure it writes the error response and returns false.
+func (s *Server) readPuzzle(w http.ResponseWriter, r *http.Request) (*parser.Puzzle, bool) {
+	data, err := s.readBody(w, r)
+	if err != nil {
+		writeBodyError(w, err)
+		return nil, false
+	}
+
+	puzzle, err := s.parsePuzzle(data, requestFormat(r))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return nil, false
+	}
+
+	return puzzle, true
+}
+
+// parsePuzzle parses a request body and checks the boards it asks for
+// are within the configured size limit. The parser refuses more than
+// parser.MaxPieces pieces as it reads, so a short body cannot expand
+// into a huge puzzle before the check.
+func (s *Server) parsePuzzle(data []byte, format parser.Format) (*parser.Puzzle, error) {
+	puzzle, err := parser.ParsePuzzle(bytes.NewReader(data), "request", format)
+	if err != nil {
+		return nil, err
+	}
+
+	_, maxSize, err := solver.SizeRange(puzzle.Pieces, puzzle.Options)
+	if err != nil {
+		return nil, err
+	}
+	if maxSize > s.cfg.MaxBoardSize {
+		return nil, fmt.Errorf("board size %d exceeds the limit of %d", maxSize, s.cfg.MaxBoardSize)
+	}
+
+	return puzzle, nil
+}
+
+// requestFormat picks the input format from the request content type
+func requestFormat(r *http.Request) parser.Format {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/json" {
+		return parser.FormatJSON
+	}
+	return parser.FormatAuto
+}
+
+// readBody reads the request body within the configured size limit
+func (s *Server) readBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	return io.ReadAll(http.MaxBytesReader(w, r.Body, s.cfg.MaxBodyBytes))
+}
+
+// solve runs the solver once a slot is free, bounded by the timeout
+func (s *Server) solve(ctx context.Context, pieces []*tetromino.Tetromino, opts solver.Options) (*solver.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	defer cancel()
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		return nil, errBusy
+	}
+
+	if s.cfg.Cache != nil {
+		return s.cfg.Cache.Solve(ctx, pieces, opts)
+	}
+	return solver.SolveOptimalContext(ctx, pieces, opts)
+}
+
+// parseVerifyInput decodes the puzzle of a verify request
+func parseVerifyInput(raw json.RawMessage) (*parser.Puzzle, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 {
+		return nil, errors.New("missing input")
+	}
+
+	if raw[0] == '"' {
+		var text string
+		if err := json.Unmarshal(raw, &text); err != nil {
+			return nil, fmt.Errorf("invalid input: %v", err)
+		}
+		return parser.ParsePuzzle(bytes.NewReader([]byte(text)), "input", parser.FormatAuto)
+	}
+
+	return parser.ParsePuzzle(bytes.NewReader(raw), "input", parser.FormatJSON)
+}
+
+// statusFor maps a solve error to the HTTP status that describes it
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, errBusy):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, context.Canceled):
+		// The client went away; the status is never seen
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusUnprocessableEntity
+	}
+}
+
+// writeBodyError reports a failure to read the request body
+func writeBodyError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("request body exceeds %d bytes", tooLarge.Limit))
+		return
+	}
+	writeError(w, http.StatusBadRequest, fmt.Errorf("cannot read request body: %v", err))
+}
+
+// writeError sends an error as a JSON body
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+// writeJSON sends a value as a JSON body
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
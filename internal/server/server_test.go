@@ -0,0 +1,153 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stkisengese/tetris-optimizer/internal/server"
+)
+
+// post sends a request body to the server and returns the recorded response
+func post(t *testing.T, srv *server.Server, path, contentType, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHealthz(t *testing.T) {
+	srv := server.New(server.Config{})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+}
+
+func TestSolveText(t *testing.T) {
+	srv := server.New(server.Config{})
+
+	rec := post(t, srv, "/solve", "text/plain", "O x4")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var doc struct {
+		Size int      `json:"size"`
+		Grid []string `json:"grid"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("Expected JSON response, got %v", err)
+	}
+
+	if doc.Size != 4 || len(doc.Grid) != 4 {
+		t.Errorf("Expected a 4x4 solution, got size %d grid %v", doc.Size, doc.Grid)
+	}
+}
+
+func TestSolveJSON(t *testing.T) {
+	srv := server.New(server.Config{})
+
+	body := `{"pieces": [{"kind": "I"}, {"kind": "I"}], "board": {"size": 4}}`
+	rec := post(t, srv, "/solve", "application/json; charset=utf-8", body)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if !strings.Contains(rec.Body.String(), `"size":4`) {
+		t.Errorf("Expected size 4 in response, got %s", rec.Body.String())
+	}
+}
+
+func TestSolveErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		cfg    server.Config
+		body   string
+		status int
+	}{
+		{name: "parse error", body: "Q", status: http.StatusBadRequest},
+		{name: "body too large", cfg: server.Config{MaxBodyBytes: 4}, body: "I O T S Z", status: http.StatusRequestEntityTooLarge},
+		{name: "too many pieces", body: "I x50000000", status: http.StatusBadRequest},
+		{name: "board too large", body: `{"pieces": [{"kind": "I"}], "board": {"size": 100000}}`, status: http.StatusBadRequest},
+		{name: "maximum size too large", body: `{"pieces": [{"kind": "I"}], "options": {"max_size": 100000}}`, status: http.StatusBadRequest},
+		{name: "pin too far", cfg: server.Config{MaxBoardSize: 8}, body: `{"pieces": [{"kind": "O", "pin": {"x": 20, "y": 0}}]}`, status: http.StatusBadRequest},
+		{name: "no solution", body: `{"pieces": [{"kind": "I"}], "board": {"size": 3}}`, status: http.StatusUnprocessableEntity},
+		{name: "timeout", cfg: server.Config{Timeout: 10 * time.Millisecond}, body: `{"pieces": [{"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}], "board": {"size": 7}}`, status: http.StatusGatewayTimeout},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := post(t, server.New(tc.cfg), "/solve", "", tc.body)
+			if rec.Code != tc.status {
+				t.Errorf("Expected status %d, got %d: %s", tc.status, rec.Code, rec.Body.String())
+			}
+
+			if !strings.Contains(rec.Body.String(), `"error"`) {
+				t.Errorf("Expected JSON error body, got %s", rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	srv := server.New(server.Config{})
+
+	testCases := []struct {
+		name  string
+		body  string
+		valid bool
+	}{
+		{name: "missing piece", body: `{"input": "O O", "grid": ["AA.", "AA.", "..."]}`, valid: false},
+		{name: "valid solution", body: `{"input": "I I", "grid": ["AAAA", "BBBB", "....", "...."]}`, valid: true},
+		{name: "json input", body: `{"input": {"pieces": [{"kind": "O"}]}, "grid": ["AA", "AA"]}`, valid: true},
+		{name: "wrong shape", body: `{"input": "T", "grid": ["AAA", "A..", "..."]}`, valid: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := post(t, srv, "/verify", "application/json", tc.body)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+			}
+
+			var resp struct {
+				Valid bool   `json:"valid"`
+				Error string `json:"error"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Expected JSON response, got %v", err)
+			}
+
+			if resp.Valid != tc.valid {
+				t.Errorf("Expected valid=%v, got %v (%s)", tc.valid, resp.Valid, resp.Error)
+			}
+		})
+	}
+
+	for _, body := range []string{`not json`, `{"grid": ["A"]}`, `{"input": "O", "grid": ["AA", "A"]}`} {
+		if rec := post(t, srv, "/verify", "application/json", body); rec.Code != http.StatusBadRequest {
+			t.Errorf("Expected status 400 for %s, got %d", body, rec.Code)
+		}
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	srv := server.New(server.Config{})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/solve", nil))
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405, got %d", rec.Code)
+	}
+}
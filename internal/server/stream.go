@@ -0,0 +1,86 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/stkisengese/tetris-optimizer/internal/report"
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+)
+
+// progressEvent is the data of a "progress" server-sent event
+type progressEvent struct {
+	Size      int      `json:"size"`
+	Nodes     int      `json:"nodes"`
+	Depth     int      `json:"depth"`
+	BestDepth int      `json:"best_depth"`
+	Best      []string `json:"best,omitempty"`
+}
+
+// handleSolveStream solves a puzzle like handleSolve, but streams the
+// search as server-sent events: "progress" events while it runs, then a
+// single "solution" or "error" event
+func (s *Server) handleSolveStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
+		return
+	}
+
+	puzzle, ok := s.readPuzzle(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	// The callback runs on this goroutine, so it may write directly
+	opts := puzzle.Options
+	opts.ProgressEvery = s.cfg.ProgressEvery
+	opts.Progress = func(p solver.Progress) {
+		writeEvent(w, "progress", newProgressEvent(p))
+		flusher.Flush()
+	}
+
+	result, err := s.solve(r.Context(), puzzle.Pieces, opts)
+	switch {
+	case err != nil:
+		writeEvent(w, "error", errorResponse{Error: err.Error()})
+	case !result.Success:
+		writeEvent(w, "error", errorResponse{Error: "no solution found"})
+	default:
+		writeEvent(w, "solution", report.NewSolution(puzzle.Pieces, result))
+	}
+	flusher.Flush()
+}
+
+// newProgressEvent converts solver progress into its event form
+func newProgressEvent(p solver.Progress) progressEvent {
+	event := progressEvent{
+		Size:      p.Size,
+		Nodes:     p.Nodes,
+		Depth:     p.Depth,
+		BestDepth: p.BestDepth,
+	}
+	if p.Best != nil {
+		event.Best = strings.Split(strings.TrimSuffix(p.Best.String(), "\n"), "\n")
+	}
+	return event
+}
+
+// writeEvent writes one server-sent event with a JSON data line
+func writeEvent(w io.Writer, name string, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		data, _ = json.Marshal(errorResponse{Error: err.Error()})
+		name = "error"
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data)
+}
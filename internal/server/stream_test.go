@@ -0,0 +1,87 @@
+package server_test
+
+import (
+	"bufio"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stkisengese/tetris-optimizer/internal/server"
+)
+
+// readEvents splits a server-sent event stream into event names and data
+func readEvents(t *testing.T, body string) ([]string, []string) {
+	var names, data []string
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			names = append(names, strings.TrimPrefix(line, "event: "))
+		case strings.HasPrefix(line, "data: "):
+			data = append(data, strings.TrimPrefix(line, "data: "))
+		}
+	}
+	if len(names) != len(data) {
+		t.Fatalf("Expected one data line per event, got %d events and %d data lines", len(names), len(data))
+	}
+	return names, data
+}
+
+func TestSolveStream(t *testing.T) {
+	srv := server.New(server.Config{ProgressEvery: 1})
+
+	rec := post(t, srv, "/solve/stream", "", "I x5")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected event stream content type, got %q", ct)
+	}
+
+	names, data := readEvents(t, rec.Body.String())
+	if len(names) < 2 {
+		t.Fatalf("Expected progress and solution events, got %v", names)
+	}
+
+	if names[0] != "progress" || !strings.Contains(data[0], `"size":5`) {
+		t.Errorf("Expected first event to announce size 5, got %s %s", names[0], data[0])
+	}
+
+	last := len(names) - 1
+	if names[last] != "solution" || !strings.Contains(data[last], `"grid"`) {
+		t.Errorf("Expected final solution event, got %s %s", names[last], data[last])
+	}
+
+	foundBest := false
+	for i, name := range names[:last] {
+		if name != "progress" {
+			t.Errorf("Expected only progress events before the solution, got %s", name)
+		}
+		if strings.Contains(data[i], `"best":[`) {
+			foundBest = true
+		}
+	}
+	if !foundBest {
+		t.Error("Expected a progress event with a best partial placement")
+	}
+}
+
+func TestSolveStreamErrors(t *testing.T) {
+	// Parse errors are reported before the stream starts
+	rec := post(t, server.New(server.Config{}), "/solve/stream", "", "Q")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+
+	// Search failures arrive as a final error event
+	srv := server.New(server.Config{Timeout: 10 * time.Millisecond})
+	rec = post(t, srv, "/solve/stream", "", `{"pieces": [{"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}, {"kind": "I"}], "board": {"size": 7}}`)
+
+	names, data := readEvents(t, rec.Body.String())
+	if len(names) == 0 || names[len(names)-1] != "error" || !strings.Contains(data[len(data)-1], "deadline") {
+		t.Errorf("Expected a final timeout error event, got %v", names)
+	}
+}
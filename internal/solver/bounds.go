@@ -0,0 +1,164 @@
+package solver
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stkisengese/tetris-optimizer/internal/grid"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// Names of the lower bounds checked before each search
+const (
+	// BoundArea: the free cells cannot hold four cells per piece
+	BoundArea = "area"
+
+	// BoundPlacement: some piece has no legal placement at all, e.g. an
+	// I piece on a 3x3 board
+	BoundPlacement = "placement"
+
+	// BoundCheckerboard: on a checkerboard colouring every piece covers
+	// two cells of each colour except T, which covers three and one, so
+	// the free cells of each colour cannot be balanced
+	BoundCheckerboard = "checkerboard"
+
+	// BoundColumns and BoundRows: the same argument with columns or rows
+	// coloured alternately, where upright I pieces cover four cells of
+	// one colour and upright T, J and L pieces three and one
+	BoundColumns = "columns"
+	BoundRows    = "rows"
+)
+
+// Infeasible records a board size that a lower bound ruled out without
+// searching, or, from Complete, that an exhaustive search ruled out
+type Infeasible struct {
+	Size   int
+	Bound  string
+	Reason string
+}
+
+// colouring assigns each cell one of two colours
+type colouring struct {
+	name   string
+	colour func(x, y int) int
+}
+
+// colourings are the two-colourings used by the parity bounds. Moving a
+// piece by one cell in the right direction swaps every colour it covers.
+var colourings = []colouring{
+	{name: BoundCheckerboard, colour: func(x, y int) int { return (x + y) % 2 }},
+	{name: BoundColumns, colour: func(x, y int) int { return x % 2 }},
+	{name: BoundRows, colour: func(x, y int) int { return y % 2 }},
+}
+
+// checkBounds applies every lower bound to a prepared board and the
+// pieces still to be placed, returning the first that proves them
+// impossible, or nil
+func checkBounds(g *grid.Grid, pieces []*tetromino.Tetromino) *Infeasible {
+	free := 0
+	for y := 0; y < g.Size; y++ {
+		for x := 0; x < g.Size; x++ {
+			if g.IsEmpty(x, y) {
+				free++
+			}
+		}
+	}
+
+	if need := 4 * len(pieces); free < need {
+		return &Infeasible{Size: g.Size, Bound: BoundArea, Reason: fmt.Sprintf("%d free cells for %d piece cells", free, need)}
+	}
+
+	var stuck []string
+	for _, t := range pieces {
+		if len(legalPlacements(g, t.ID, t.Orientations())) == 0 {
+			stuck = append(stuck, string(t.ID))
+		}
+	}
+	if len(stuck) > 0 {
+		return &Infeasible{Size: g.Size, Bound: BoundPlacement, Reason: fmt.Sprintf("no room for piece %s", strings.Join(stuck, ", "))}
+	}
+
+	for _, c := range colourings {
+		if reason := parityBound(g, pieces, c); reason != "" {
+			return &Infeasible{Size: g.Size, Bound: c.name, Reason: reason}
+		}
+	}
+
+	return nil
+}
+
+// parityBound checks whether the pieces can cover cells of both colours
+// in a mix the free cells allow. Each piece covers between zero and four
+// more cells of the first colour than of the second, depending on its
+// orientation and position; the bound fails if no sum of those
+// differences fits. It returns the reason when it does.
+func parityBound(g *grid.Grid, pieces []*tetromino.Tetromino, c colouring) string {
+	var free [2]int
+	for y := 0; y < g.Size; y++ {
+		for x := 0; x < g.Size; x++ {
+			if g.IsEmpty(x, y) {
+				free[c.colour(x, y)]++
+			}
+		}
+	}
+
+	// reachable[d+offset] is true when the pieces so far can cover d more
+	// cells of colour 0 than of colour 1
+	offset := 4 * len(pieces)
+	reachable := make([]bool, 2*offset+1)
+	reachable[offset] = true
+
+	for _, t := range pieces {
+		diffs := pieceDiffs(t, c)
+		next := make([]bool, len(reachable))
+		for d, ok := range reachable {
+			if !ok {
+				continue
+			}
+			for _, diff := range diffs {
+				next[d+diff] = true
+			}
+		}
+		reachable = next
+	}
+
+	// Covering n cells with difference d uses (n+d)/2 cells of colour 0
+	// and (n-d)/2 of colour 1
+	cells := 4 * len(pieces)
+	for d, ok := range reachable {
+		diff := d - offset
+		if ok && (cells+diff)/2 <= free[0] && (cells-diff)/2 <= free[1] {
+			return ""
+		}
+	}
+
+	return fmt.Sprintf("%d and %d free cells of each colour cannot fit the pieces' colour counts", free[0], free[1])
+}
+
+// pieceDiffs returns the differences between the colour 0 and colour 1
+// cells a piece can cover in its allowed orientations
+func pieceDiffs(t *tetromino.Tetromino, c colouring) []int {
+	seen := make(map[int]bool)
+	var diffs []int
+
+	for _, rotation := range t.Orientations() {
+		diff := 0
+		for _, p := range rotation.Cells() {
+			if c.colour(p.X, p.Y) == 0 {
+				diff++
+			} else {
+				diff--
+			}
+		}
+
+		// A one-cell shift swaps the colours
+		for _, d := range []int{diff, -diff} {
+			if !seen[d] {
+				seen[d] = true
+				diffs = append(diffs, d)
+			}
+		}
+	}
+
+	return diffs
+}
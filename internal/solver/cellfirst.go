@@ -0,0 +1,190 @@
+package solver
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/stkisengese/tetris-optimizer/internal/grid"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+func init() {
+	Register("cell-first", cellFirst{})
+}
+
+// cellFirst is the "cell-first" strategy. Instead of branching on the
+// next piece, it branches on the top-left-most empty cell: either some
+// remaining piece covers it, or it stays empty. Cells before the cursor
+// are always settled, so no gap can be left behind unnoticed, and a cell
+// may only stay empty while the board has spare cells (slack) left.
+type cellFirst struct{}
+
+// cellPiece is a piece waiting to be placed. The search covers a cell
+// with the placements of the table anchored on it, which cover no cell
+// before it.
+type cellPiece struct {
+	table pieceTable
+
+	// class is shared by pieces with the same allowed orientations, so
+	// only one of a set of identical pieces is tried at each cell;
+	// twins indexes the earlier pieces of the same class
+	class string
+	twins []int
+	used  bool
+}
+
+// cellSearch is the state of one cell-first solve
+type cellSearch struct {
+	*search
+	g      *grid.Grid
+	pieces []cellPiece
+	placed int
+
+	// found, when set, is called with each complete board; the search
+	// goes on to the next solution unless it returns true
+	found func(*grid.Grid) bool
+}
+
+// Solve implements Solver
+func (cellFirst) Solve(ctx context.Context, pieces []*tetromino.Tetromino, board *grid.Grid, opts Options) (*Result, error) {
+	cs := newCellSearch(ctx, pieces, board, opts)
+	success := cs.run()
+	if cs.err != nil {
+		return nil, cs.err
+	}
+
+	return &Result{
+		Grid:    board,
+		Success: success,
+		Size:    board.Size,
+		Nodes:   cs.nodes,
+	}, nil
+}
+
+// newCellSearch prepares a cell-first search of the pieces on board
+func newCellSearch(ctx context.Context, pieces []*tetromino.Tetromino, board *grid.Grid, opts Options) *cellSearch {
+	cs := &cellSearch{
+		search: newSearch(ctx, opts),
+		g:      board,
+		pieces: make([]cellPiece, len(pieces)),
+	}
+	cs.startSize(board)
+
+	tables := newTables(board, pieces)
+	for i, t := range pieces {
+		cs.pieces[i] = cellPiece{table: tables[i], class: pieceClass(t)}
+		for j := range i {
+			if cs.pieces[j].class == cs.pieces[i].class {
+				cs.pieces[i].twins = append(cs.pieces[i].twins, j)
+			}
+		}
+	}
+	return cs
+}
+
+// run searches from the first cell, with the board's spare cells as
+// slack
+func (cs *cellSearch) run() bool {
+	free := 0
+	for y := 0; y < cs.g.Size; y++ {
+		for x := 0; x < cs.g.Size; x++ {
+			if cs.g.IsEmpty(x, y) {
+				free++
+			}
+		}
+	}
+
+	slack := free - 4*len(cs.pieces)
+	return slack >= 0 && cs.fill(0, slack)
+}
+
+// pieceClass names the set of orientations a piece may take, so that
+// identical pieces share a class whichever rotation they were given in
+func pieceClass(t *tetromino.Tetromino) string {
+	orientations := t.Orientations()
+	keys := make([]string, len(orientations))
+	for i, o := range orientations {
+		keys[i] = o.Key()
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, "|")
+}
+
+// fill settles every cell from index pos onwards, in row-major order,
+// with slack cells allowed to stay empty
+func (cs *cellSearch) fill(pos, slack int) bool {
+	if cs.placed == len(cs.pieces) {
+		if cs.found != nil {
+			return cs.found(cs.g)
+		}
+		return true
+	}
+
+	// Skip cells that are already covered or blocked
+	size := cs.g.Size
+	for pos < size*size && !cs.g.IsEmpty(pos%size, pos/size) {
+		pos++
+	}
+	if pos == size*size {
+		return false
+	}
+
+	cs.nodes++
+	if cs.cancelled() {
+		return false
+	}
+	if cs.progress != nil {
+		cs.track(cs.g, cs.placed)
+	}
+
+	for i := range cs.pieces {
+		piece := &cs.pieces[i]
+		if piece.used || cs.twinWaiting(piece) {
+			continue
+		}
+
+		for _, k := range piece.table.anchoredAt(pos) {
+			p := &piece.table.placements[k]
+			if !p.fits(cs.g) {
+				continue
+			}
+			if err := cs.g.Place(p.Placement); err != nil {
+				continue
+			}
+
+			piece.used = true
+			cs.placed++
+
+			if cs.fill(pos+1, slack) {
+				return true
+			}
+
+			cs.placed--
+			piece.used = false
+			cs.g.Remove(p.Placement)
+
+			if cs.err != nil {
+				return false
+			}
+		}
+	}
+
+	// Leave this cell empty if the board can spare it
+	if slack > 0 {
+		return cs.fill(pos+1, slack-1)
+	}
+
+	return false
+}
+
+// twinWaiting reports whether an identical piece earlier in the list is
+// still unplaced, in which case it has already been tried at this cell
+func (cs *cellSearch) twinWaiting(piece *cellPiece) bool {
+	for _, j := range piece.twins {
+		if !cs.pieces[j].used {
+			return true
+		}
+	}
+	return false
+}
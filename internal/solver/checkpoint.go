@@ -0,0 +1,94 @@
+package solver
+
+import (
+	"fmt"
+
+	"github.com/stkisengese/tetris-optimizer/internal/grid"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// DefaultCheckpointEvery is the number of nodes between checkpoints when
+// Options.CheckpointEvery is zero
+const DefaultCheckpointEvery = 100000
+
+// Choice is one placement on the search stack: the piece placed at that
+// depth, the index of its orientation among its allowed rotations, and
+// where it went
+type Choice struct {
+	Piece       string `json:"piece"`
+	Orientation int    `json:"orientation"`
+	X           int    `json:"x"`
+	Y           int    `json:"y"`
+}
+
+// Checkpoint is the state of a backtrack search, enough to continue it
+// later. Every size below Size has been searched, and at Size every
+// branch before Stack has been explored.
+type Checkpoint struct {
+	Size  int      `json:"size"`
+	Nodes int      `json:"nodes"`
+	Stack []Choice `json:"stack"`
+}
+
+// before reports whether orientation r at (x, y) comes before the
+// choice in the order backtrack tries placements
+func (c Choice) before(r, x, y int) bool {
+	if r != c.Orientation {
+		return r < c.Orientation
+	}
+	if y != c.Y {
+		return y < c.Y
+	}
+	return x < c.X
+}
+
+// checkpointable checks that the options select a search that can be
+// checkpointed: the backtrack strategy with a static piece order
+func checkpointable(opts Options) error {
+	if opts.Strategy != "" && opts.Strategy != DefaultStrategy {
+		return fmt.Errorf("checkpoints need the %s strategy, got %q", DefaultStrategy, opts.Strategy)
+	}
+	if opts.Order == OrderDynamic {
+		return fmt.Errorf("checkpoints do not support the %s order", OrderDynamic)
+	}
+	return nil
+}
+
+// saveCheckpoint reports the current stack every s.checkpointEvery
+// nodes
+func (s *search) saveCheckpoint(depth int) {
+	if s.nodes%s.checkpointEvery != 0 {
+		return
+	}
+
+	stack := make([]Choice, depth)
+	copy(stack, s.path[:depth])
+	s.checkpoint(Checkpoint{Size: s.size, Nodes: s.nodes, Stack: stack})
+}
+
+// checkResume checks that a checkpoint stack can be replayed on the
+// board with the pieces in search order
+func checkResume(board *grid.Grid, pieces []*tetromino.Tetromino, stack []Choice) error {
+	if len(stack) > len(pieces) {
+		return fmt.Errorf("checkpoint stack has %d choices for %d pieces", len(stack), len(pieces))
+	}
+
+	g := board.Clone()
+	for depth, choice := range stack {
+		piece := pieces[depth]
+		if choice.Piece != string(piece.ID) {
+			return fmt.Errorf("checkpoint places piece %s at depth %d, expected %c", choice.Piece, depth, piece.ID)
+		}
+
+		rotations := piece.Orientations()
+		if choice.Orientation < 0 || choice.Orientation >= len(rotations) {
+			return fmt.Errorf("checkpoint orientation %d of piece %s is out of range", choice.Orientation, choice.Piece)
+		}
+		placement := grid.Placement{Piece: piece.ID, Orientation: rotations[choice.Orientation], X: choice.X, Y: choice.Y}
+		if err := g.Place(placement); err != nil {
+			return fmt.Errorf("checkpoint does not match the board: %v", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stkisengese/tetris-optimizer/internal/grid"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// BoundSearch names the proof Complete gives when no lower bound rules
+// the board out but an exhaustive search finds no completion
+const BoundSearch = "search"
+
+// Complete tries to place the remaining pieces on a partly filled board.
+// Every cell that is not empty ('.') is kept as it is, so pieces already
+// placed and blocked cells are fixed; pinned pieces among the remaining
+// ones are placed first. The board itself is not modified.
+//
+// On success Result.Grid is the completed board. Otherwise the result
+// carries a proof in Result.Infeasible: the lower bound that rules the
+// board out, or BoundSearch when the whole search came up empty. The
+// board size, mask, checkpoint and resume options do not apply, and the
+// heuristic strategy is refused since it cannot prove a board impossible.
+func Complete(ctx context.Context, g *grid.Grid, remaining []*tetromino.Tetromino, opts Options) (*Result, error) {
+	if g == nil || g.Size <= 0 || len(g.Cells) != g.Size {
+		return nil, fmt.Errorf("there is no square board to complete")
+	}
+	for y, row := range g.Cells {
+		if len(row) != g.Size {
+			return nil, fmt.Errorf("board must be square: row %d has %d cells, expected %d", y, len(row), g.Size)
+		}
+	}
+	if opts.Size != 0 || opts.MaxSize != 0 || opts.Mask != nil {
+		return nil, fmt.Errorf("the board to complete fixes the size and blocked cells")
+	}
+	if opts.Checkpoint != nil || opts.Resume != nil {
+		return nil, fmt.Errorf("checkpoints are not supported when completing a board")
+	}
+
+	strategy, err := Lookup(opts.Strategy)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckOrder(opts.Order); err != nil {
+		return nil, err
+	}
+	if _, ok := strategy.(anytimeSolver); ok {
+		return nil, fmt.Errorf("strategy %q cannot prove a board impossible", opts.Strategy)
+	}
+
+	board := g.Clone()
+	if len(remaining) == 0 {
+		return &Result{Grid: board, Success: true, Size: board.Size}, nil
+	}
+
+	result, err := solveOnGrid(ctx, strategy, board, remaining, opts)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Success && len(result.Infeasible) == 0 {
+		result.Infeasible = []Infeasible{{
+			Size:   board.Size,
+			Bound:  BoundSearch,
+			Reason: fmt.Sprintf("no completion in %d search nodes", result.Nodes),
+		}}
+	}
+	return result, nil
+}
@@ -0,0 +1,148 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/stkisengese/tetris-optimizer/internal/grid"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// Count is the outcome of CountSolutions
+type Count struct {
+	// Solutions is the number of distinct solutions found
+	Solutions int
+
+	// Complete reports that the whole board was searched, so Solutions
+	// is exact; otherwise the count stopped at the limit
+	Complete bool
+
+	// Grid is the first solution found, or nil when there is none
+	Grid *grid.Grid
+
+	// Nodes counts the search nodes explored
+	Nodes int
+}
+
+// CountSolutions counts the ways to pack the pieces on a size x size
+// board, with any mask and pinned pieces of opts applied. Solutions that
+// differ only by swapping identical pieces, or by rotating or reflecting
+// the board, count once. The count stops after limit solutions; zero or
+// less counts them all.
+func CountSolutions(ctx context.Context, tetrominoes []*tetromino.Tetromino, size, limit int, opts Options) (*Count, error) {
+	if len(tetrominoes) == 0 {
+		return nil, fmt.Errorf("there are no pieces to count solutions for")
+	}
+	if opts.Mask != nil && len(opts.Mask) != size {
+		return nil, fmt.Errorf("board size %d does not match %dx%d mask", size, len(opts.Mask), len(opts.Mask))
+	}
+
+	board, err := newBoard(size, opts.Mask)
+	if err != nil {
+		return nil, err
+	}
+	remaining, err := placePinned(board, tetrominoes)
+	if err != nil {
+		return nil, err
+	}
+
+	count := &Count{}
+	if len(remaining) == 0 {
+		count.Solutions, count.Complete, count.Grid = 1, true, board
+		return count, nil
+	}
+	if !opts.SkipBounds && checkBounds(board, remaining) != nil {
+		count.Complete = true
+		return count, nil
+	}
+
+	classes := pieceClasses(tetrominoes)
+	seen := make(map[string]bool)
+
+	cs := newCellSearch(ctx, remaining, board, opts)
+	cs.found = func(g *grid.Grid) bool {
+		key := canonicalKey(g, classes)
+		if seen[key] {
+			return false
+		}
+		seen[key] = true
+		if count.Grid == nil {
+			count.Grid = g.Clone()
+		}
+		return limit > 0 && len(seen) >= limit
+	}
+
+	stopped := cs.run()
+	if cs.err != nil {
+		return nil, cs.err
+	}
+
+	count.Solutions = len(seen)
+	count.Complete = !stopped
+	count.Nodes = cs.nodes
+	return count, nil
+}
+
+// pieceClasses numbers the distinct piece shapes, mapping each piece ID
+// to the number of its shape. Identical pieces share a number.
+func pieceClasses(tetrominoes []*tetromino.Tetromino) map[rune]int {
+	numbers := make(map[string]int)
+	classes := make(map[rune]int, len(tetrominoes))
+	for _, t := range tetrominoes {
+		class := pieceClass(t)
+		if _, ok := numbers[class]; !ok {
+			numbers[class] = len(numbers)
+		}
+		classes[t.ID] = numbers[class]
+	}
+	return classes
+}
+
+// symmetries map a cell (x, y) of an n x n board to its image under each
+// rotation and reflection of the board
+var symmetries = []func(x, y, n int) (int, int){
+	func(x, y, n int) (int, int) { return x, y },
+	func(x, y, n int) (int, int) { return n - 1 - y, x },
+	func(x, y, n int) (int, int) { return n - 1 - x, n - 1 - y },
+	func(x, y, n int) (int, int) { return y, n - 1 - x },
+	func(x, y, n int) (int, int) { return n - 1 - x, y },
+	func(x, y, n int) (int, int) { return x, n - 1 - y },
+	func(x, y, n int) (int, int) { return y, x },
+	func(x, y, n int) (int, int) { return n - 1 - y, n - 1 - x },
+}
+
+// canonicalKey describes a packed board so that boards equal up to
+// symmetry, and up to swapping identical pieces, get the same key. Each
+// image of the board is written out with pieces renumbered in order of
+// appearance and tagged with their shape; the smallest image is the key.
+func canonicalKey(g *grid.Grid, classes map[rune]int) string {
+	n := g.Size
+	var key string
+	for i, symmetry := range symmetries {
+		var b strings.Builder
+		labels := make(map[rune]int)
+		for y := 0; y < n; y++ {
+			for x := 0; x < n; x++ {
+				sx, sy := symmetry(x, y, n)
+				cell := g.Cells[sy][sx]
+				if cell == '.' || cell == grid.Blocked {
+					b.WriteRune(cell)
+					continue
+				}
+
+				label, ok := labels[cell]
+				if !ok {
+					label = len(labels)
+					labels[cell] = label
+				}
+				b.WriteString(strconv.Itoa(label) + ":" + strconv.Itoa(classes[cell]) + ",")
+			}
+		}
+		if image := b.String(); i == 0 || image < key {
+			key = image
+		}
+	}
+	return key
+}
@@ -0,0 +1,336 @@
+package solver
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/stkisengese/tetris-optimizer/internal/grid"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// DefaultTimeLimit is how long the heuristic strategy searches when
+// Options.TimeLimit is zero
+const DefaultTimeLimit = 2 * time.Second
+
+// Annealing schedule: the temperature starts at annealStart, cools by
+// annealCooling after every move and reheats once it drops below
+// annealFloor, so the search keeps escaping local minima until the time
+// runs out
+const (
+	annealStart   = 1.0
+	annealCooling = 0.995
+	annealFloor   = 0.02
+)
+
+// quickMoves is the number of annealing moves spent on each size while
+// looking for a first board that fits
+const quickMoves = 200
+
+func init() {
+	Register("heuristic", heuristic{})
+}
+
+// anytimeSolver is implemented by strategies that choose which board
+// sizes to try themselves instead of being run on each size in turn
+type anytimeSolver interface {
+	solveRange(ctx context.Context, tetrominoes []*tetromino.Tetromino, minSize, maxSize int, opts Options) (*Result, error)
+}
+
+// Anytime reports whether the named strategy is an anytime one, whose
+// result depends on Options.TimeLimit and need not be optimal
+func Anytime(name string) bool {
+	strategy, err := Lookup(name)
+	if err != nil {
+		return false
+	}
+	_, ok := strategy.(anytimeSolver)
+	return ok
+}
+
+// heuristic is the "heuristic" strategy for inputs too large for exact
+// search. A candidate is a piece order plus a preferred orientation for
+// each piece; it is decoded by greedy first fit, placing each piece in
+// turn where it covers the earliest empty cell in reading order.
+// Simulated annealing over the candidates minimises the number of pieces
+// that do not fit. Failing to fit proves nothing, so a board it gives up
+// on may still be solvable.
+type heuristic struct{}
+
+// Solve implements Solver. It anneals on the board until every piece
+// fits or Options.TimeLimit passes.
+func (heuristic) Solve(ctx context.Context, pieces []*tetromino.Tetromino, board *grid.Grid, opts Options) (*Result, error) {
+	s := newSearch(ctx, opts)
+	s.startSize(board)
+
+	deadline := time.Now().Add(timeLimit(opts))
+	solved := newPacker(s, board, pieces).anneal(deadline, 0)
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	result := &Result{Grid: board, Size: board.Size, Nodes: s.nodes}
+	if solved != nil {
+		result.Grid = solved
+		result.Success = true
+	}
+	return result, nil
+}
+
+// solveRange is the anytime search used by SolveOptimalContext. It
+// first climbs from minSize until a quick anneal fits every piece, then
+// spends the rest of the time limit trying to shrink that board one size
+// at a time. The best board found is returned; it is only Optimal when
+// every smaller size was ruled out by a lower bound.
+func (heuristic) solveRange(ctx context.Context, tetrominoes []*tetromino.Tetromino, minSize, maxSize int, opts Options) (*Result, error) {
+	s := newSearch(ctx, opts)
+	deadline := time.Now().Add(timeLimit(opts))
+
+	ruledOut := make(map[int]Infeasible)
+	var best *Result
+
+	// attempt anneals at one size, returning the packed board or nil
+	attempt := func(size, moves int) (*grid.Grid, error) {
+		g, err := newBoard(size, opts.Mask)
+		if err != nil {
+			return nil, err
+		}
+		remaining, err := placePinned(g, tetrominoes)
+		if err != nil {
+			return nil, err
+		}
+		if !opts.SkipBounds {
+			if proof := checkBounds(g, remaining); proof != nil {
+				ruledOut[size] = *proof
+				return nil, nil
+			}
+		}
+
+		s.startSize(g)
+		solved := newPacker(s, g, orderPieces(g, remaining, opts.Order)).anneal(deadline, moves)
+		return solved, s.err
+	}
+
+	// Find a first board that fits, spending little time on each size
+	// and all the remaining time on the largest
+	for size := minSize; size <= maxSize && best == nil; size++ {
+		moves := quickMoves
+		if size == maxSize {
+			moves = 0
+		}
+		solved, err := attempt(size, moves)
+		if err != nil {
+			return nil, err
+		}
+		if solved != nil {
+			best = &Result{Grid: solved, Success: true, Size: size}
+		}
+		if time.Now().After(deadline) {
+			break
+		}
+	}
+
+	// Shrink the board while time remains
+	if best != nil {
+		for size := best.Size - 1; size >= minSize && time.Now().Before(deadline); size-- {
+			solved, err := attempt(size, 0)
+			if err != nil {
+				return nil, err
+			}
+			if solved != nil {
+				best = &Result{Grid: solved, Success: true, Size: size}
+				continue
+			}
+			if _, ok := ruledOut[size]; !ok {
+				break
+			}
+		}
+	}
+
+	if best == nil {
+		g, err := newBoard(maxSize, opts.Mask)
+		if err != nil {
+			return nil, err
+		}
+		best = &Result{Grid: g, Size: maxSize}
+	}
+	best.Nodes = s.nodes
+
+	best.Optimal = best.Success
+	for size := minSize; size < best.Size; size++ {
+		proof, ok := ruledOut[size]
+		if !ok {
+			best.Optimal = false
+			continue
+		}
+		best.Infeasible = append(best.Infeasible, proof)
+	}
+
+	return best, nil
+}
+
+// timeLimit returns the heuristic's time budget
+func timeLimit(opts Options) time.Duration {
+	if opts.TimeLimit > 0 {
+		return opts.TimeLimit
+	}
+	return DefaultTimeLimit
+}
+
+// packer decodes and anneals candidates for one board
+type packer struct {
+	*search
+	board     *grid.Grid
+	pieces    []*tetromino.Tetromino
+	rotations [][]tetromino.Orientation
+	rng       *rand.Rand
+}
+
+// newPacker prepares the orientations of each piece for the board. The
+// random source is seeded from the board size and piece count so runs
+// are repeatable.
+func newPacker(s *search, board *grid.Grid, pieces []*tetromino.Tetromino) *packer {
+	p := &packer{
+		search:    s,
+		board:     board,
+		pieces:    pieces,
+		rotations: make([][]tetromino.Orientation, len(pieces)),
+		rng:       rand.New(rand.NewSource(int64(board.Size*100 + len(pieces)))),
+	}
+
+	for i, t := range pieces {
+		p.rotations[i] = t.Orientations()
+	}
+
+	return p
+}
+
+// anneal searches until every piece fits, the deadline passes or, when
+// moves is positive, that many moves have been tried. It returns the
+// packed board, or nil.
+func (p *packer) anneal(deadline time.Time, moves int) *grid.Grid {
+	n := len(p.rotations)
+	order := make([]int, n)
+	prefer := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+
+	g, unplaced := p.decode(order, prefer)
+	if unplaced == 0 {
+		return g
+	}
+
+	nextOrder := make([]int, n)
+	nextPrefer := make([]int, n)
+	temperature := annealStart
+	for move := 1; moves <= 0 || move <= moves; move++ {
+		if p.cancelled() {
+			return nil
+		}
+		if move%64 == 0 && time.Now().After(deadline) {
+			return nil
+		}
+
+		copy(nextOrder, order)
+		copy(nextPrefer, prefer)
+		p.mutate(nextOrder, nextPrefer)
+
+		next, nextUnplaced := p.decode(nextOrder, nextPrefer)
+		if nextUnplaced == 0 {
+			return next
+		}
+
+		delta := float64(nextUnplaced - unplaced)
+		if delta <= 0 || p.rng.Float64() < math.Exp(-delta/temperature) {
+			order, nextOrder = nextOrder, order
+			prefer, nextPrefer = nextPrefer, prefer
+			unplaced = nextUnplaced
+		}
+
+		temperature *= annealCooling
+		if temperature < annealFloor {
+			temperature = annealStart
+		}
+	}
+
+	return nil
+}
+
+// mutate applies one random move to a candidate: swapping two pieces,
+// moving a piece to the front, or changing a preferred orientation
+func (p *packer) mutate(order, prefer []int) {
+	n := len(order)
+	switch p.rng.Intn(3) {
+	case 0:
+		i, j := p.rng.Intn(n), p.rng.Intn(n)
+		order[i], order[j] = order[j], order[i]
+	case 1:
+		i := p.rng.Intn(n)
+		piece := order[i]
+		copy(order[1:i+1], order[:i])
+		order[0] = piece
+	default:
+		i := p.rng.Intn(n)
+		prefer[i] = p.rng.Intn(len(p.rotations[i]))
+	}
+}
+
+// decode packs a candidate by greedy first fit. Each piece goes where
+// its first cell lands earliest in reading order, ties going to the
+// preferred orientation; pieces that fit nowhere are skipped and
+// counted.
+func (p *packer) decode(order, prefer []int) (*grid.Grid, int) {
+	p.nodes++
+	g := p.board.Clone()
+	unplaced := 0
+	placed := 0
+
+	for _, piece := range order {
+		rotations := p.rotations[piece]
+		best, bestX, bestY, bestCell := -1, 0, 0, g.Size*g.Size
+
+		for k := range rotations {
+			j := (prefer[piece] + k) % len(rotations)
+			rotation := rotations[j]
+			x, y, ok := firstFit(g, rotation.Shape)
+			if !ok {
+				continue
+			}
+			anchor := rotation.Cells()[0]
+			if cell := (y+anchor.Y)*g.Size + x + anchor.X; cell < bestCell {
+				best, bestX, bestY, bestCell = j, x, y, cell
+			}
+		}
+
+		if best < 0 {
+			unplaced++
+			continue
+		}
+		placement := grid.Placement{Piece: p.pieces[piece].ID, Orientation: rotations[best], X: bestX, Y: bestY}
+		if err := g.Place(placement); err != nil {
+			unplaced++
+			continue
+		}
+		placed++
+	}
+
+	if p.progress != nil {
+		p.track(g, placed)
+	}
+
+	return g, unplaced
+}
+
+// firstFit finds the first position in reading order where a shape fits
+func firstFit(g *grid.Grid, shape tetromino.Shape) (int, int, bool) {
+	for y := 0; y <= g.Size-shape.Height(); y++ {
+		for x := 0; x <= g.Size-shape.Width(); x++ {
+			if g.CanPlace(shape, x, y) {
+				return x, y, true
+			}
+		}
+	}
+	return 0, 0, false
+}
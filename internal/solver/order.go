@@ -0,0 +1,156 @@
+package solver
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/stkisengese/tetris-optimizer/internal/grid"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// Piece orderings for Options.Order. Orderings only change the order in
+// which the search tries pieces; every piece keeps its ID, so solutions
+// are always labelled as in the input.
+const (
+	// OrderInput tries pieces in input order
+	OrderInput = "input"
+
+	// OrderHardest tries awkward shapes first: S and Z, then T, then J
+	// and L, then I and finally O
+	OrderHardest = "hardest"
+
+	// OrderFewestPlacements tries the pieces with the fewest legal
+	// placements on the starting board first
+	OrderFewestPlacements = "fewest-placements"
+
+	// OrderDynamic picks, at every node, the unplaced piece with the
+	// fewest legal placements on the current board. Only the backtrack
+	// strategy supports it; other strategies fall back to input order.
+	OrderDynamic = "dynamic"
+)
+
+// Orders lists the supported piece orderings
+var Orders = []string{OrderInput, OrderHardest, OrderFewestPlacements, OrderDynamic}
+
+// hardness ranks kinds for OrderHardest; lower ranks go first
+var hardness = map[tetromino.Kind]int{
+	tetromino.KindS: 0,
+	tetromino.KindZ: 0,
+	tetromino.KindT: 1,
+	tetromino.KindJ: 2,
+	tetromino.KindL: 2,
+	tetromino.KindI: 3,
+	tetromino.KindO: 4,
+}
+
+// CheckOrder validates an ordering name; empty means OrderInput
+func CheckOrder(order string) error {
+	if order == "" {
+		return nil
+	}
+	for _, name := range Orders {
+		if order == name {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown piece order %q, expected one of %v", order, Orders)
+}
+
+// orderPieces returns the pieces in the order the search should try
+// them on the prepared board g. Dynamic ordering happens during the
+// search, so it leaves the order unchanged here.
+func orderPieces(g *grid.Grid, tetrominoes []*tetromino.Tetromino, order string) []*tetromino.Tetromino {
+	ordered := make([]*tetromino.Tetromino, len(tetrominoes))
+	copy(ordered, tetrominoes)
+
+	switch order {
+	case OrderHardest:
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return hardness[ordered[i].Kind] < hardness[ordered[j].Kind]
+		})
+
+	case OrderFewestPlacements:
+		counts := make(map[*tetromino.Tetromino]int, len(ordered))
+		for _, t := range ordered {
+			counts[t] = len(legalPlacements(g, t.ID, t.Orientations()))
+		}
+		sort.SliceStable(ordered, func(i, j int) bool {
+			return counts[ordered[i]] < counts[ordered[j]]
+		})
+	}
+
+	return ordered
+}
+
+// legalPlacements lists every placement of a piece that fits on g
+func legalPlacements(g *grid.Grid, id rune, orientations []tetromino.Orientation) []grid.Placement {
+	var placements []grid.Placement
+	for _, o := range orientations {
+		for y := 0; y <= g.Size-o.Height(); y++ {
+			for x := 0; x <= g.Size-o.Width(); x++ {
+				if g.CanPlace(o.Shape, x, y) {
+					placements = append(placements, grid.Placement{Piece: id, Orientation: o, X: x, Y: y})
+				}
+			}
+		}
+	}
+	return placements
+}
+
+// backtrackDynamic is backtrack with most-constrained-piece ordering:
+// at each node it branches on the unplaced piece with the fewest legal
+// placements, failing at once if any piece has none
+func (s *search) backtrackDynamic(g *grid.Grid, tables []pieceTable, used []bool, depth int) bool {
+	if depth == len(tables) {
+		return true
+	}
+
+	s.nodes++
+	if s.cancelled() {
+		return false
+	}
+	if s.progress != nil {
+		s.track(g, depth)
+	}
+
+	// Counting stops at the best count so far, since only a smaller
+	// count can take its place
+	best, bestCount := -1, math.MaxInt
+	for i := range tables {
+		if used[i] {
+			continue
+		}
+		count := tables[i].fitting(g, bestCount)
+		if count == 0 {
+			return false
+		}
+		if count < bestCount {
+			best, bestCount = i, count
+		}
+	}
+
+	used[best] = true
+	placements := tables[best].placements
+	for i := range placements {
+		p := &placements[i]
+		if !p.fits(g) {
+			continue
+		}
+		if err := g.Place(p.Placement); err != nil {
+			continue
+		}
+
+		if s.backtrackDynamic(g, tables, used, depth+1) {
+			return true
+		}
+		if s.err != nil {
+			return false
+		}
+
+		g.Remove(p.Placement)
+	}
+	used[best] = false
+
+	return false
+}
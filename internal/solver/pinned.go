@@ -0,0 +1,75 @@
+package solver
+
+import (
+	"fmt"
+
+	"github.com/stkisengese/tetris-optimizer/internal/grid"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// placePinned puts every pinned piece on the grid at its fixed position
+// and returns the pieces left for the search
+func placePinned(g *grid.Grid, tetrominoes []*tetromino.Tetromino) ([]*tetromino.Tetromino, error) {
+	remaining := make([]*tetromino.Tetromino, 0, len(tetrominoes))
+
+	for _, t := range tetrominoes {
+		if t.Pin == nil {
+			remaining = append(remaining, t)
+			continue
+		}
+
+		p := grid.Placement{Piece: t.ID, Orientation: t.Orientation(t.Pin.Rotation), X: t.Pin.X, Y: t.Pin.Y}
+		if err := checkPinned(g, p); err != nil {
+			return nil, err
+		}
+
+		if err := g.Place(p); err != nil {
+			return nil, err
+		}
+	}
+
+	return remaining, nil
+}
+
+// checkPinned explains why a pinned piece cannot go where it was pinned
+func checkPinned(g *grid.Grid, p grid.Placement) error {
+	id, x, y := p.Piece, p.X, p.Y
+	for _, cell := range p.Cells() {
+		px, py := cell.X, cell.Y
+
+		if !g.IsValidPosition(px, py) {
+			return fmt.Errorf("pinned piece %c at (%d, %d) does not fit on a %dx%d board", id, x, y, g.Size, g.Size)
+		}
+
+		switch cell := g.Cells[py][px]; {
+		case cell == grid.Blocked:
+			return fmt.Errorf("pinned piece %c at (%d, %d) covers blocked cell (%d, %d)", id, x, y, px, py)
+		case cell != '.':
+			return fmt.Errorf("pinned piece %c at (%d, %d) overlaps pinned piece %c at cell (%d, %d)", id, x, y, cell, px, py)
+		}
+	}
+
+	return nil
+}
+
+// pinnedExtent returns the smallest board size that holds every pinned
+// piece at its fixed position
+func pinnedExtent(tetrominoes []*tetromino.Tetromino) int {
+	extent := 0
+
+	for _, t := range tetrominoes {
+		if t.Pin == nil {
+			continue
+		}
+
+		oriented := t.Orientation(t.Pin.Rotation)
+		if right := t.Pin.X + oriented.Width(); right > extent {
+			extent = right
+		}
+		if bottom := t.Pin.Y + oriented.Height(); bottom > extent {
+			extent = bottom
+		}
+	}
+
+	return extent
+}
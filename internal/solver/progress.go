@@ -0,0 +1,91 @@
+package solver
+
+import (
+	"context"
+
+	"github.com/stkisengese/tetris-optimizer/internal/grid"
+)
+
+// DefaultProgressEvery is the number of nodes between progress reports
+// when Options.ProgressEvery is zero
+const DefaultProgressEvery = 10000
+
+// Progress describes a running search
+type Progress struct {
+	// Size is the board size currently being tried
+	Size int
+
+	// Nodes counts the search nodes explored so far across every size
+	Nodes int
+
+	// Depth is the number of pieces placed at the current node
+	Depth int
+
+	// BestDepth is the most pieces placed at once at this size, and Best
+	// a snapshot of that partial placement. Best is nil until the first
+	// piece has been placed.
+	BestDepth int
+	Best      *grid.Grid
+}
+
+// newSearch prepares the state for one solve
+func newSearch(ctx context.Context, opts Options) *search {
+	every := opts.ProgressEvery
+	if every <= 0 {
+		every = DefaultProgressEvery
+	}
+
+	checkpointEvery := opts.CheckpointEvery
+	if checkpointEvery <= 0 {
+		checkpointEvery = DefaultCheckpointEvery
+	}
+
+	return &search{
+		ctx:             ctx,
+		progress:        opts.Progress,
+		every:           every,
+		checkpoint:      opts.Checkpoint,
+		checkpointEvery: checkpointEvery,
+	}
+}
+
+// startSize resets the per-size progress state and announces the size
+func (s *search) startSize(g *grid.Grid) {
+	s.size = g.Size
+	s.bestDepth = 0
+	s.best = nil
+	s.report(0)
+}
+
+// track records the deepest partial placement and reports progress
+// every s.every nodes
+func (s *search) track(g *grid.Grid, depth int) {
+	if depth > s.bestDepth {
+		s.bestDepth = depth
+		s.best = g.Clone()
+	}
+
+	if s.nodes%s.every == 0 {
+		s.report(depth)
+	}
+}
+
+// report calls the progress callback, if any
+func (s *search) report(depth int) {
+	if s.progress == nil {
+		return
+	}
+
+	var best *grid.Grid
+	if s.best != nil {
+		best = s.best.Clone()
+	}
+
+	s.progress(Progress{
+		Size:      s.size,
+		Nodes:     s.nodes,
+		Depth:     depth,
+		BestDepth: s.bestDepth,
+		Best:      best,
+	})
+}
@@ -0,0 +1,183 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/stkisengese/tetris-optimizer/internal/grid"
+	"github.com/stkisengese/tetris-optimizer/internal/sat"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+func init() {
+	Register("sat", satStrategy{})
+}
+
+// Encoding is the CNF form of "these pieces fit on this board". Each of
+// the first len(Placements) variables selects one placement; any further
+// variables are auxiliary.
+type Encoding struct {
+	CNF        *sat.CNF
+	Placements []SATPlacement
+}
+
+// SATPlacement is the placement selected by variable index+1 of an
+// Encoding
+type SATPlacement struct {
+	// Piece is the index of the piece in the encoded list
+	Piece     int
+	Placement grid.Placement
+}
+
+// Encode builds the CNF for packing pieces onto a prepared board, whose
+// blocked and already covered cells are left alone. It requires exactly
+// one placement per piece and at most one piece per cell. Identical
+// pieces are interchangeable, so each is also required to take a later
+// placement than the previous one, which removes equivalent solutions.
+func Encode(pieces []*tetromino.Tetromino, board *grid.Grid) *Encoding {
+	enc := &Encoding{CNF: &sat.CNF{}}
+	byPiece := make([][]int, len(pieces))
+	byCell := make([][]int, board.Size*board.Size)
+	previous := make(map[string]int)
+
+	for i, t := range pieces {
+		for _, p := range legalPlacements(board, t.ID, t.Orientations()) {
+			enc.Placements = append(enc.Placements, SATPlacement{Piece: i, Placement: p})
+			v := enc.CNF.NewVar()
+			byPiece[i] = append(byPiece[i], v)
+
+			for _, point := range p.Cells() {
+				cell := point.Y*board.Size + point.X
+				byCell[cell] = append(byCell[cell], v)
+			}
+		}
+	}
+
+	for _, vars := range byPiece {
+		// A piece with no placement gives an empty clause, which is
+		// unsatisfiable as it should be
+		enc.CNF.ExactlyOne(vars...)
+	}
+
+	for i, t := range pieces {
+		class := orientationKey(t)
+		if j, ok := previous[class]; ok {
+			orderPlacements(enc.CNF, byPiece[j], byPiece[i])
+		}
+		previous[class] = i
+	}
+	for _, vars := range byCell {
+		if len(vars) > 1 {
+			enc.CNF.AtMostOne(vars...)
+		}
+	}
+
+	return enc
+}
+
+// orientationKey identifies a piece by its orientations in the order
+// legalPlacements lists them. Identical pieces given in different
+// rotations get different keys: their placements come in a different
+// order, which orderPlacements cannot pair up.
+func orientationKey(t *tetromino.Tetromino) string {
+	orientations := t.Orientations()
+	keys := make([]string, len(orientations))
+	for i, o := range orientations {
+		keys[i] = o.Key()
+	}
+	return strings.Join(keys, "|")
+}
+
+// orderPlacements requires the placement chosen from later to come after
+// the one chosen from earlier. Both list the same placements in the same
+// order. prefix[k] is implied by earlier choosing one of its first k+1
+// placements.
+func orderPlacements(cnf *sat.CNF, earlier, later []int) {
+	if len(later) == 0 {
+		return
+	}
+	cnf.AddClause(-later[0])
+
+	prefix := make([]int, len(earlier))
+	for k, x := range earlier {
+		prefix[k] = cnf.NewVar()
+		if k == 0 {
+			cnf.AddClause(-prefix[k], x)
+		} else {
+			cnf.AddClause(-prefix[k], x, prefix[k-1])
+		}
+	}
+
+	for k := 1; k < len(later); k++ {
+		cnf.AddClause(-later[k], prefix[k-1])
+	}
+}
+
+// EncodeSize builds the encoding for a board of the given size, with
+// the options' mask applied and pinned pieces already placed
+func EncodeSize(tetrominoes []*tetromino.Tetromino, size int, opts Options) (*Encoding, error) {
+	if opts.Mask != nil && len(opts.Mask) != size {
+		return nil, fmt.Errorf("board size %d does not match %dx%d mask", size, len(opts.Mask), len(opts.Mask))
+	}
+
+	board, err := newBoard(size, opts.Mask)
+	if err != nil {
+		return nil, err
+	}
+
+	remaining, err := placePinned(board, tetrominoes)
+	if err != nil {
+		return nil, err
+	}
+
+	return Encode(remaining, board), nil
+}
+
+// Comments describes every placement variable, for DIMACS export
+func (e *Encoding) Comments() []string {
+	comments := make([]string, len(e.Placements))
+	for i, p := range e.Placements {
+		placement := p.Placement
+		comments[i] = fmt.Sprintf("var %d = piece %c at (%d, %d) shape %s", i+1, placement.Piece, placement.X, placement.Y, placement.Orientation.Key())
+	}
+	return comments
+}
+
+// satStrategy is the "sat" strategy: it encodes the board as CNF and
+// hands it to the built-in CDCL solver. Nodes counts solver decisions.
+type satStrategy struct{}
+
+// Solve implements Solver
+func (satStrategy) Solve(ctx context.Context, pieces []*tetromino.Tetromino, board *grid.Grid, opts Options) (*Result, error) {
+	s := newSearch(ctx, opts)
+	s.startSize(board)
+
+	enc := Encode(pieces, board)
+	solver := sat.NewSolverCNF(enc.CNF)
+
+	ok, err := solver.Solve(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok {
+		for i, p := range enc.Placements {
+			if !solver.Value(i + 1) {
+				continue
+			}
+			if err := board.Place(p.Placement); err != nil {
+				return nil, fmt.Errorf("invalid SAT model: %v", err)
+			}
+		}
+	}
+
+	s.nodes = solver.Stats().Decisions
+
+	return &Result{
+		Grid:    board,
+		Success: ok,
+		Size:    board.Size,
+		Nodes:   s.nodes,
+	}, nil
+}
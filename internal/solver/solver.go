@@ -1,8 +1,10 @@
 package solver
 
 import (
+	"context"
 	"fmt"
 	"math"
+	"time"
 
 	"github.com/stkisengese/tetris-optimizer/internal/grid"
 	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
@@ -13,6 +15,71 @@ type Result struct {
 	Grid    *grid.Grid
 	Success bool
 	Size    int
+
+	// Nodes counts the search nodes explored across every size tried
+	Nodes int
+
+	// Infeasible lists the sizes that lower bounds ruled out before any
+	// search, smallest first
+	Infeasible []Infeasible
+
+	// Optimal reports that no smaller board allowed by the options fits
+	// the pieces. Exact strategies prove it by searching every smaller
+	// size; the heuristic only when lower bounds rule them all out.
+	Optimal bool
+}
+
+// Options configures SolveOptimalWithOptions
+type Options struct {
+	// Size fixes the board size instead of searching for the smallest
+	// square. Zero searches upward from the theoretical minimum.
+	Size int
+
+	// MaxSize caps the largest square tried while searching. Zero uses
+	// the theoretical minimum plus four.
+	MaxSize int
+
+	// Mask lists cells, indexed [y][x], that no piece may cover. When
+	// set, its length fixes the board size.
+	Mask [][]bool
+
+	// Progress, when set, is called from the searching goroutine as each
+	// size starts and then every ProgressEvery nodes
+	Progress func(Progress)
+
+	// ProgressEvery sets how many nodes pass between progress reports.
+	// Zero uses DefaultProgressEvery.
+	ProgressEvery int
+
+	// Strategy names the registered Solver to use. Empty selects
+	// DefaultStrategy.
+	Strategy string
+
+	// Order is the piece ordering applied before the search, one of
+	// Orders. Empty keeps input order.
+	Order string
+
+	// SkipBounds disables the lower-bound checks, so every size is
+	// searched
+	SkipBounds bool
+
+	// TimeLimit bounds how long the heuristic strategy searches before
+	// returning its best board. Zero uses DefaultTimeLimit; exact
+	// strategies ignore it.
+	TimeLimit time.Duration
+
+	// Checkpoint, when set, is called from the searching goroutine every
+	// CheckpointEvery nodes with the state needed to resume the search.
+	// Only the backtrack strategy with a static order supports it.
+	Checkpoint func(Checkpoint)
+
+	// CheckpointEvery sets how many nodes pass between checkpoints. Zero
+	// uses DefaultCheckpointEvery.
+	CheckpointEvery int
+
+	// Resume continues the search from a checkpoint taken with the same
+	// pieces and options
+	Resume *Checkpoint
 }
 
 // CalculateMinSquareSize calculates the theoretical minimum square size
@@ -40,47 +107,143 @@ func SolveTetris(tetrominoes []*tetromino.Tetromino, gridSize int) (*Result, err
 		return nil, fmt.Errorf("failed to create grid: %v", err)
 	}
 
-	success := backtrack(g, tetrominoes, 0)
+	return solveOnGrid(context.Background(), backtracker{}, g, tetrominoes, Options{})
+}
+
+// newBoard creates a grid of the given size with masked cells blocked
+func newBoard(size int, mask [][]bool) (*grid.Grid, error) {
+	g, err := grid.NewGrid(size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create grid: %v", err)
+	}
+
+	for y, row := range mask {
+		for x, blocked := range row {
+			if !blocked {
+				continue
+			}
+			if err := g.Block(x, y); err != nil {
+				return nil, fmt.Errorf("invalid board mask: %v", err)
+			}
+		}
+	}
+
+	return g, nil
+}
+
+// search carries the state of one solve through the recursion
+type search struct {
+	ctx   context.Context
+	nodes int
+	err   error
 
-	return &Result{
-		Grid:    g,
-		Success: success,
-		Size:    gridSize,
-	}, nil
+	// progress reporting; best holds the deepest partial placement seen
+	// at the current size and is only tracked when progress is set
+	progress  func(Progress)
+	every     int
+	size      int
+	bestDepth int
+	best      *grid.Grid
+
+	// checkpointing; path holds the choice made at each depth and is
+	// only tracked when checkpoint is set, resume the stack to restore
+	checkpoint      func(Checkpoint)
+	checkpointEvery int
+	path            []Choice
+	resume          []Choice
+}
+
+// cancelCheckInterval is how many nodes are explored between checks of
+// the context, keeping the check off the hot path
+const cancelCheckInterval = 1024
+
+// cancelled records and reports whether the search should stop
+func (s *search) cancelled() bool {
+	if s.err != nil {
+		return true
+	}
+	if s.nodes%cancelCheckInterval == 0 {
+		s.err = s.ctx.Err()
+	}
+	return s.err != nil
 }
 
-// backtrack implements simple recursive backtracking
-func backtrack(g *grid.Grid, tetrominoes []*tetromino.Tetromino, index int) bool {
+// solveOnGrid places any pinned pieces, checks the lower bounds, then
+// runs the strategy for the rest, in the requested order, on a prepared
+// grid
+func solveOnGrid(ctx context.Context, strategy Solver, g *grid.Grid, tetrominoes []*tetromino.Tetromino, opts Options) (*Result, error) {
+	remaining, err := placePinned(g, tetrominoes)
+	if err != nil {
+		return nil, err
+	}
+
+	if !opts.SkipBounds {
+		if proof := checkBounds(g, remaining); proof != nil {
+			return &Result{Grid: g, Size: g.Size, Infeasible: []Infeasible{*proof}}, nil
+		}
+	}
+
+	return strategy.Solve(ctx, orderPieces(g, remaining, opts.Order), g, opts)
+}
+
+// backtrack implements simple recursive backtracking, placing the
+// pieces of tables in order
+func (s *search) backtrack(g *grid.Grid, tables []pieceTable, index int) bool {
 	// Base case: all tetrominoes placed
-	if index >= len(tetrominoes) {
+	if index >= len(tables) {
 		return true
 	}
 
-	current := tetrominoes[index]
-
-	// Try all possible rotations
-	rotations := current.GenerateRotations()
-	for _, rotation := range rotations {
-		// Try all possible positions
-		for y := 0; y <= g.Size-rotation.Height; y++ {
-			for x := 0; x <= g.Size-rotation.Width; x++ {
-				if g.CanPlaceTetromino(rotation, x, y) {
-					// Place the piece/tetromino
-					err := g.PlaceTetromino(rotation, x, y)
-					if err != nil {
-						continue
-					}
-
-					// Recursively try to place the next tetromino
-					if backtrack(g, tetrominoes, index+1) {
-						return true
-					}
-
-					// Backtrack: remove the tetromino
-					g.RemoveTetromino(rotation)
-				}
-			}
+	s.nodes++
+	if s.cancelled() {
+		return false
+	}
+	if s.progress != nil {
+		s.track(g, index)
+	}
+	if s.checkpoint != nil {
+		s.saveCheckpoint(index)
+	}
+
+	// When resuming, nodes on the checkpointed stack skip the branches
+	// explored before it was saved
+	var start Choice
+	resuming := index < len(s.resume)
+	if resuming {
+		start = s.resume[index]
+	} else {
+		s.resume = nil
+	}
+
+	// Try every orientation at every position, in table order
+	placements := tables[index].placements
+	for i := range placements {
+		p := &placements[i]
+		if resuming && start.before(p.orientation, p.X, p.Y) {
+			continue
+		}
+		if !p.fits(g) {
+			continue
+		}
+
+		// Place the piece/tetromino
+		if err := g.Place(p.Placement); err != nil {
+			continue
 		}
+		if s.path != nil {
+			s.path[index] = Choice{Piece: string(p.Piece), Orientation: p.orientation, X: p.X, Y: p.Y}
+		}
+
+		// Recursively try to place the next tetromino
+		if s.backtrack(g, tables, index+1) {
+			return true
+		}
+		if s.err != nil {
+			return false
+		}
+
+		// Backtrack: remove the tetromino
+		g.Remove(p.Placement)
 	}
 
 	return false
@@ -88,25 +251,142 @@ func backtrack(g *grid.Grid, tetrominoes []*tetromino.Tetromino, index int) bool
 
 // SolveOptimal finds the optimal solution by trying increasing grid sizes
 func SolveOptimal(tetrominoes []*tetromino.Tetromino) (*Result, error) {
+	return SolveOptimalWithOptions(tetrominoes, Options{})
+}
+
+// SolveOptimalWithOptions finds the smallest square allowed by the
+// options that fits every tetromino
+func SolveOptimalWithOptions(tetrominoes []*tetromino.Tetromino, opts Options) (*Result, error) {
+	return SolveOptimalContext(context.Background(), tetrominoes, opts)
+}
+
+// SolveOptimalContext is SolveOptimalWithOptions with cancellation: once
+// ctx is done the search stops and returns the context's error
+func SolveOptimalContext(ctx context.Context, tetrominoes []*tetromino.Tetromino, opts Options) (*Result, error) {
 	if len(tetrominoes) == 0 {
 		return &Result{Success: false, Size: 0}, nil
 	}
 
-	// Calculate minimum possible size
-	minSize := CalculateMinSquareSize(tetrominoes)
+	minSize, maxSize, err := SizeRange(tetrominoes, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	strategy, err := Lookup(opts.Strategy)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckOrder(opts.Order); err != nil {
+		return nil, err
+	}
+
+	if opts.Checkpoint != nil || opts.Resume != nil {
+		if err := checkpointable(opts); err != nil {
+			return nil, err
+		}
+	}
+
+	// Anytime strategies choose which sizes to try themselves
+	if anytime, ok := strategy.(anytimeSolver); ok {
+		return anytime.solveRange(ctx, tetrominoes, minSize, maxSize, opts)
+	}
+
+	// A resumed search picks up at the checkpointed size
+	if opts.Resume != nil {
+		if opts.Resume.Size < minSize || opts.Resume.Size > maxSize {
+			return nil, fmt.Errorf("checkpoint size %d is outside the sizes %d to %d", opts.Resume.Size, minSize, maxSize)
+		}
+		minSize = opts.Resume.Size
+	}
+
+	// Strategies count nodes per board; offset progress reports and
+	// checkpoints so they count across every size tried
+	nodes := 0
+	if opts.Resume != nil {
+		nodes = opts.Resume.Nodes
+	}
+	sizeOpts := opts
+	if opts.Progress != nil {
+		sizeOpts.Progress = func(p Progress) {
+			p.Nodes += nodes
+			opts.Progress(p)
+		}
+	}
+	if opts.Checkpoint != nil {
+		sizeOpts.Checkpoint = func(c Checkpoint) {
+			c.Nodes += nodes
+			opts.Checkpoint(c)
+		}
+	}
 
 	// Try increasing sizes until we find a solution
-	for size := minSize; size <= minSize+4; size++ { // Reasonable upper bound
-		result, err := SolveTetris(tetrominoes, size)
+	var result *Result
+	var infeasible []Infeasible
+	for size := minSize; size <= maxSize; size++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		g, err := newBoard(size, opts.Mask)
 		if err != nil {
 			return nil, err
 		}
 
+		result, err = solveOnGrid(ctx, strategy, g, tetrominoes, sizeOpts)
+		if err != nil {
+			return nil, err
+		}
+		sizeOpts.Resume = nil
+		nodes += result.Nodes
+		result.Nodes = nodes
+		infeasible = append(infeasible, result.Infeasible...)
+		result.Infeasible = infeasible
 		if result.Success {
+			result.Optimal = true
 			return result, nil
 		}
 	}
 
-	// If no solution found in reasonable range, return the last attempt
-	return SolveTetris(tetrominoes, minSize+4)
+	// If no solution found in range, return the last attempt
+	return result, nil
+}
+
+// SizeRange works out the smallest and largest board sizes the options
+// allow for the given pieces
+func SizeRange(tetrominoes []*tetromino.Tetromino, opts Options) (int, int, error) {
+	if opts.Mask != nil {
+		size := len(opts.Mask)
+		for y, row := range opts.Mask {
+			if len(row) != size {
+				return 0, 0, fmt.Errorf("board mask must be square: row %d has %d cells, expected %d", y, len(row), size)
+			}
+		}
+		if opts.Size != 0 && opts.Size != size {
+			return 0, 0, fmt.Errorf("board size %d does not match %dx%d mask", opts.Size, size, size)
+		}
+		return size, size, nil
+	}
+
+	if opts.Size < 0 || opts.MaxSize < 0 {
+		return 0, 0, fmt.Errorf("board sizes must not be negative")
+	}
+
+	if opts.Size > 0 {
+		return opts.Size, opts.Size, nil
+	}
+
+	// Calculate minimum possible size, leaving room for pinned pieces
+	minSize := CalculateMinSquareSize(tetrominoes)
+	if extent := pinnedExtent(tetrominoes); extent > minSize {
+		minSize = extent
+	}
+	maxSize := minSize + 4 // Reasonable upper bound
+	if opts.MaxSize > 0 {
+		maxSize = opts.MaxSize
+	}
+	if maxSize < minSize {
+		return 0, 0, fmt.Errorf("maximum size %d is below the minimum size %d", maxSize, minSize)
+	}
+
+	return minSize, maxSize, nil
 }
@@ -1,8 +1,14 @@
 package solver_test
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/stkisengese/tetris-optimizer/internal/grid"
+	"github.com/stkisengese/tetris-optimizer/internal/sat"
 	"github.com/stkisengese/tetris-optimizer/internal/solver"
 	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
 )
@@ -217,3 +223,844 @@ func BenchmarkSolveTetris(b *testing.B) {
 		solver.SolveTetris(tetrominoes, 3)
 	}
 }
+
+// benchmarkSearch packs a fixed set of nine pieces with the lower bounds
+// off, so the time and allocations reported are those of the search
+func benchmarkSearch(b *testing.B, opts solver.Options) {
+	pieces := standardPieces(b, "IOTSZJLTL")
+	opts.SkipBounds = true
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := solver.SolveOptimalWithOptions(pieces, opts); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBacktrack(b *testing.B) {
+	benchmarkSearch(b, solver.Options{})
+}
+
+func BenchmarkBacktrackDynamic(b *testing.B) {
+	benchmarkSearch(b, solver.Options{Order: solver.OrderDynamic})
+}
+
+func BenchmarkCellFirst(b *testing.B) {
+	benchmarkSearch(b, solver.Options{Strategy: "cell-first"})
+}
+
+func TestSolveOptimalWithOptions(t *testing.T) {
+	// Two O pieces need a 3x3 board at minimum
+	pieces := append(createSquarePiece(), createSquarePiece()...)
+
+	result, err := solver.SolveOptimalWithOptions(pieces, solver.Options{Size: 4})
+	if err != nil {
+		t.Fatalf("SolveOptimalWithOptions() error = %v", err)
+	}
+	if !result.Success || result.Size != 4 {
+		t.Errorf("Expected a fixed 4x4 solution, got success=%v size=%d", result.Success, result.Size)
+	}
+
+	// Blocking the centre of a 3x3 board leaves no room for an O piece
+	mask := [][]bool{
+		{false, false, false},
+		{false, true, false},
+		{false, false, false},
+	}
+	result, err = solver.SolveOptimalWithOptions(createSquarePiece(), solver.Options{Mask: mask})
+	if err != nil {
+		t.Fatalf("SolveOptimalWithOptions() error = %v", err)
+	}
+	if result.Success {
+		t.Error("Expected no solution with the centre cell blocked")
+	}
+
+	// An L piece still fits around the blocked centre
+	result, err = solver.SolveOptimalWithOptions(createLPiece(), solver.Options{Mask: mask})
+	if err != nil {
+		t.Fatalf("SolveOptimalWithOptions() error = %v", err)
+	}
+	if !result.Success {
+		t.Error("Expected L piece to fit around the blocked centre")
+	}
+}
+
+func TestSolveOptimalWithInvalidOptions(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts solver.Options
+	}{
+		{name: "negative size", opts: solver.Options{Size: -1}},
+		{name: "max below min", opts: solver.Options{MaxSize: 1}},
+		{name: "ragged mask", opts: solver.Options{Mask: [][]bool{{false, false}, {false}}}},
+		{name: "size disagrees with mask", opts: solver.Options{Size: 3, Mask: [][]bool{{false, false}, {false, false}}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := solver.SolveOptimalWithOptions(createLPiece(), tc.opts); err == nil {
+				t.Errorf("Expected error for %s, got nil", tc.name)
+			}
+		})
+	}
+}
+
+func TestSolveRespectsAllowedRotations(t *testing.T) {
+	// A vertical I piece restricted to one orientation only fits a
+	// 1-wide column if that orientation is upright
+	pieces := createIPiece()
+	pieces[0].Rotations = []int{0}
+
+	mask := make([][]bool, 4)
+	for y := range mask {
+		mask[y] = []bool{true, true, true, false}
+	}
+
+	result, err := solver.SolveOptimalWithOptions(pieces, solver.Options{Mask: mask})
+	if err != nil {
+		t.Fatalf("SolveOptimalWithOptions() error = %v", err)
+	}
+	if !result.Success {
+		t.Error("Expected the vertical I piece to fit the free column")
+	}
+
+	pieces[0].Rotations = []int{1}
+	result, err = solver.SolveOptimalWithOptions(pieces, solver.Options{Mask: mask})
+	if err != nil {
+		t.Fatalf("SolveOptimalWithOptions() error = %v", err)
+	}
+	if result.Success {
+		t.Error("Expected the horizontal I piece not to fit the free column")
+	}
+}
+
+func TestSolvePinnedPieces(t *testing.T) {
+	// Pin an O piece in the bottom-right corner of a 4x4 board
+	pinned := createSquarePiece()[0]
+	pinned.ID = 'P'
+	pinned.Pin = &tetromino.Pin{X: 2, Y: 2}
+
+	pieces := append(createSquarePiece(), pinned)
+
+	result, err := solver.SolveOptimal(pieces)
+	if err != nil {
+		t.Fatalf("SolveOptimal() error = %v", err)
+	}
+
+	if !result.Success || result.Size != 4 {
+		t.Fatalf("Expected a 4x4 solution, got success=%v size=%d", result.Success, result.Size)
+	}
+
+	if result.Grid.Cells[2][2] != 'P' || result.Grid.Cells[3][3] != 'P' {
+		t.Errorf("Expected pinned piece at (2, 2), got:\n%s", result.Grid.String())
+	}
+
+	if result.Grid.Cells[0][0] != 'A' {
+		t.Errorf("Expected free piece to be searched into the corner, got:\n%s", result.Grid.String())
+	}
+}
+
+func TestSolvePinnedConflicts(t *testing.T) {
+	first := createSquarePiece()[0]
+	first.Pin = &tetromino.Pin{X: 0, Y: 0}
+
+	second := createSquarePiece()[0]
+	second.ID = 'B'
+	second.Pin = &tetromino.Pin{X: 1, Y: 1}
+
+	_, err := solver.SolveOptimal([]*tetromino.Tetromino{first, second})
+	if err == nil || !strings.Contains(err.Error(), "overlaps pinned piece A") {
+		t.Errorf("Expected overlap error, got %v", err)
+	}
+
+	// A pin outside a fixed board cannot be honoured
+	_, err = solver.SolveOptimalWithOptions([]*tetromino.Tetromino{second}, solver.Options{Size: 2})
+	if err == nil || !strings.Contains(err.Error(), "does not fit") {
+		t.Errorf("Expected out of bounds error, got %v", err)
+	}
+
+	// A pin on a masked cell is rejected
+	mask := [][]bool{{true, false}, {false, false}}
+	_, err = solver.SolveOptimalWithOptions([]*tetromino.Tetromino{first}, solver.Options{Mask: mask})
+	if err == nil || !strings.Contains(err.Error(), "blocked cell") {
+		t.Errorf("Expected blocked cell error, got %v", err)
+	}
+}
+
+func TestSolveOptimalContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := solver.SolveOptimalContext(ctx, createTestTetrominoes(4), solver.Options{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestSolveOptimalContextTimeout(t *testing.T) {
+	// Twelve I pieces leave no room for error on a 7x7 board, so the
+	// search runs far longer than the deadline
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := solver.SolveOptimalContext(ctx, createTestTetrominoes(12), solver.Options{MaxSize: 7})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the search to stop promptly, took %v", elapsed)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	pieces := append(createLPiece(), createSquarePiece()...)
+	pieces[1].ID = 'B'
+
+	result, err := solver.SolveOptimal(pieces)
+	if err != nil || !result.Success {
+		t.Fatalf("SolveOptimal() failed: success=%v err=%v", result != nil && result.Success, err)
+	}
+
+	if err := solver.Verify(pieces, result.Grid); err != nil {
+		t.Errorf("Expected solver output to verify, got %v", err)
+	}
+
+	testCases := []struct {
+		name string
+		rows []string
+	}{
+		{name: "missing piece", rows: []string{"A..", "A..", "AA."}},
+		{name: "wrong shape", rows: []string{"AAA", "ABB", "BB."}},
+		{name: "too many cells", rows: []string{"ABB", "ABB", "AAA"}},
+		{name: "unknown piece", rows: []string{"ABB", "ABB", "AAC"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g, err := grid.FromRows(tc.rows)
+			if err != nil {
+				t.Fatalf("FromRows() error = %v", err)
+			}
+			if err := solver.Verify(pieces, g); err == nil {
+				t.Errorf("Expected %s to fail verification", tc.name)
+			}
+		})
+	}
+}
+
+func TestSolveOptimalProgress(t *testing.T) {
+	var events []solver.Progress
+	opts := solver.Options{
+		ProgressEvery: 1,
+		Progress: func(p solver.Progress) {
+			events = append(events, p)
+		},
+	}
+
+	// Five I pieces fill one row each of a 5x5 board
+	result, err := solver.SolveOptimalWithOptions(createTestTetrominoes(5), opts)
+	if err != nil {
+		t.Fatalf("SolveOptimalWithOptions() error = %v", err)
+	}
+
+	if len(events) == 0 {
+		t.Fatal("Expected progress events")
+	}
+
+	if events[0].Size != solver.CalculateMinSquareSize(createTestTetrominoes(5)) || events[0].Nodes != 0 {
+		t.Errorf("Expected the first event to announce the starting size, got %+v", events[0])
+	}
+
+	last := events[len(events)-1]
+	if last.Size != result.Size {
+		t.Errorf("Expected last event at size %d, got %d", result.Size, last.Size)
+	}
+
+	for i := 1; i < len(events); i++ {
+		if events[i].Nodes < events[i-1].Nodes {
+			t.Fatalf("Expected node counts to grow, got %d after %d", events[i].Nodes, events[i-1].Nodes)
+		}
+	}
+
+	if last.BestDepth == 0 || last.Best == nil {
+		t.Errorf("Expected a best partial placement, got depth %d", last.BestDepth)
+	}
+
+	if result.Nodes < last.Nodes {
+		t.Errorf("Expected result to count at least %d nodes, got %d", last.Nodes, result.Nodes)
+	}
+}
+
+// stubSolver records the boards it is asked to fill and never succeeds
+type stubSolver struct {
+	sizes []int
+}
+
+func (s *stubSolver) Solve(ctx context.Context, pieces []*tetromino.Tetromino, board *grid.Grid, opts solver.Options) (*solver.Result, error) {
+	s.sizes = append(s.sizes, board.Size)
+	return &solver.Result{Grid: board, Size: board.Size, Nodes: 1}, nil
+}
+
+func TestStrategyRegistry(t *testing.T) {
+	if _, err := solver.Lookup(""); err != nil {
+		t.Errorf("Expected the default strategy, got %v", err)
+	}
+	if _, err := solver.Lookup("no-such-strategy"); err == nil {
+		t.Error("Expected error for unknown strategy, got nil")
+	}
+
+	stub := &stubSolver{}
+	solver.Register("stub", stub)
+
+	found := false
+	for _, name := range solver.Strategies() {
+		found = found || name == "stub"
+	}
+	if !found {
+		t.Errorf("Expected stub in %v", solver.Strategies())
+	}
+
+	result, err := solver.SolveOptimalWithOptions(createTestTetrominoes(1), solver.Options{Strategy: "stub", MaxSize: 4, SkipBounds: true})
+	if err != nil {
+		t.Fatalf("SolveOptimalWithOptions() error = %v", err)
+	}
+
+	if len(stub.sizes) != 3 || stub.sizes[0] != 2 || stub.sizes[2] != 4 {
+		t.Errorf("Expected the stub to be tried on sizes 2 to 4, got %v", stub.sizes)
+	}
+	if result.Success || result.Nodes != 3 {
+		t.Errorf("Expected a failed result counting 3 nodes, got %+v", result)
+	}
+
+	if _, err := solver.SolveOptimalWithOptions(createTestTetrominoes(1), solver.Options{Strategy: "no-such-strategy"}); err == nil {
+		t.Error("Expected error for unknown strategy, got nil")
+	}
+}
+
+// standardPieces builds one standard tetromino per letter, labelled A, B, ...
+func standardPieces(t testing.TB, letters string) []*tetromino.Tetromino {
+	var pieces []*tetromino.Tetromino
+	for i, letter := range strings.ReplaceAll(letters, " ", "") {
+		kind, ok := tetromino.ParseKind(string(letter))
+		if !ok {
+			t.Fatalf("Unknown kind %c", letter)
+		}
+		piece, err := tetromino.NewStandard(rune('A'+i), kind)
+		if err != nil {
+			t.Fatalf("NewStandard() error = %v", err)
+		}
+		pieces = append(pieces, piece)
+	}
+	return pieces
+}
+
+// TestStrategiesAgree checks every strategy finds the same board size as
+// backtrack, with a valid packing
+func TestStrategiesAgree(t *testing.T) {
+	testCases := []struct {
+		name   string
+		pieces func(t *testing.T) []*tetromino.Tetromino
+		opts   solver.Options
+	}{
+		{name: "mixed pieces", pieces: func(t *testing.T) []*tetromino.Tetromino {
+			return standardPieces(t, "IOTSZJL")
+		}},
+		{name: "repeated pieces", pieces: func(t *testing.T) []*tetromino.Tetromino {
+			return standardPieces(t, "TTTTLL")
+		}},
+		{name: "pinned piece", pieces: func(t *testing.T) []*tetromino.Tetromino {
+			pieces := standardPieces(t, "TOI")
+			pieces[0].Pin = &tetromino.Pin{X: 1, Y: 1, Rotation: 1}
+			return pieces
+		}},
+		{name: "identical pieces in different rotations", pieces: func(t *testing.T) []*tetromino.Tetromino {
+			pieces := standardPieces(t, "IIII")
+			pieces[0].Rotate90()
+			pieces[2].Rotate90()
+			return pieces
+		}},
+		{name: "restricted rotations", pieces: func(t *testing.T) []*tetromino.Tetromino {
+			pieces := standardPieces(t, "IIO")
+			pieces[0].Rotations = []int{1}
+			pieces[1].Rotations = []int{1}
+			return pieces
+		}},
+		{name: "masked board", pieces: func(t *testing.T) []*tetromino.Tetromino {
+			return standardPieces(t, "OO")
+		}, opts: solver.Options{Mask: [][]bool{
+			{true, false, false, false},
+			{false, false, false, false},
+			{false, false, false, false},
+			{false, false, false, true},
+		}}},
+	}
+
+	for _, strategy := range []string{"cell-first", "sat"} {
+		for _, tc := range testCases {
+			t.Run(strategy+"/"+tc.name, func(t *testing.T) {
+				want, err := solver.SolveOptimalWithOptions(tc.pieces(t), tc.opts)
+				if err != nil {
+					t.Fatalf("backtrack error = %v", err)
+				}
+
+				opts := tc.opts
+				opts.Strategy = strategy
+				pieces := tc.pieces(t)
+				got, err := solver.SolveOptimalWithOptions(pieces, opts)
+				if err != nil {
+					t.Fatalf("%s error = %v", strategy, err)
+				}
+
+				if got.Success != want.Success || got.Size != want.Size {
+					t.Fatalf("Expected success=%v size %d, got success=%v size %d", want.Success, want.Size, got.Success, got.Size)
+				}
+				if got.Success {
+					if !got.Optimal {
+						t.Error("Expected an exact strategy to report an optimal result")
+					}
+					if err := solver.Verify(pieces, got.Grid); err != nil {
+						t.Errorf("Expected a valid grid, got %v\n%s", err, got.Grid)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestEncodeSize(t *testing.T) {
+	// Two O pieces on a 3x3 board: 4 placements each, never both at once
+	enc, err := solver.EncodeSize(standardPieces(t, "OO"), 3, solver.Options{})
+	if err != nil {
+		t.Fatalf("EncodeSize() error = %v", err)
+	}
+
+	if len(enc.Placements) != 8 {
+		t.Errorf("Expected 8 placement variables, got %d", len(enc.Placements))
+	}
+	if len(enc.Comments()) != len(enc.Placements) {
+		t.Errorf("Expected one comment per placement, got %d", len(enc.Comments()))
+	}
+
+	if ok, err := sat.NewSolverCNF(enc.CNF).Solve(context.Background()); err != nil || ok {
+		t.Errorf("Expected two O pieces not to fit a 3x3 board, got sat=%v err=%v", ok, err)
+	}
+
+	if _, err := solver.EncodeSize(standardPieces(t, "O"), 3, solver.Options{Mask: make([][]bool, 4)}); err == nil {
+		t.Error("Expected error for a size that does not match the mask, got nil")
+	}
+}
+
+func TestCellFirstExploresFewerNodes(t *testing.T) {
+	letters := "TTTTLLSZI"
+
+	backtrack, err := solver.SolveOptimalWithOptions(standardPieces(t, letters), solver.Options{})
+	if err != nil {
+		t.Fatalf("backtrack error = %v", err)
+	}
+
+	cellFirst, err := solver.SolveOptimalWithOptions(standardPieces(t, letters), solver.Options{Strategy: "cell-first"})
+	if err != nil {
+		t.Fatalf("cell-first error = %v", err)
+	}
+
+	if cellFirst.Size != backtrack.Size {
+		t.Fatalf("Expected size %d, got %d", backtrack.Size, cellFirst.Size)
+	}
+	if cellFirst.Nodes >= backtrack.Nodes {
+		t.Errorf("Expected fewer nodes than backtrack's %d, got %d", backtrack.Nodes, cellFirst.Nodes)
+	}
+}
+
+func TestPieceOrders(t *testing.T) {
+	for _, order := range solver.Orders {
+		t.Run(order, func(t *testing.T) {
+			pieces := standardPieces(t, "OITSZLJ")
+			result, err := solver.SolveOptimalWithOptions(pieces, solver.Options{Order: order})
+			if err != nil {
+				t.Fatalf("SolveOptimalWithOptions() error = %v", err)
+			}
+
+			if !result.Success || result.Size != 6 {
+				t.Fatalf("Expected a 6x6 solution, got success=%v size=%d", result.Success, result.Size)
+			}
+
+			// Reordering must not relabel: A is still the O piece, and so on
+			if err := solver.Verify(pieces, result.Grid); err != nil {
+				t.Errorf("Expected a grid labelled as the input, got %v\n%s", err, result.Grid)
+			}
+		})
+	}
+
+	if _, err := solver.SolveOptimalWithOptions(standardPieces(t, "O"), solver.Options{Order: "random"}); err == nil {
+		t.Error("Expected error for unknown order, got nil")
+	}
+}
+
+func TestHardestOrderPlacesSFirst(t *testing.T) {
+	pieces := standardPieces(t, "OS")
+	var first rune
+	opts := solver.Options{
+		Order:         solver.OrderHardest,
+		ProgressEvery: 1,
+		Progress: func(p solver.Progress) {
+			if first == 0 && p.Best != nil {
+				for _, row := range p.Best.Cells {
+					for _, cell := range row {
+						if cell != '.' {
+							first = cell
+						}
+					}
+				}
+			}
+		},
+	}
+
+	if _, err := solver.SolveOptimalWithOptions(pieces, opts); err != nil {
+		t.Fatalf("SolveOptimalWithOptions() error = %v", err)
+	}
+	if first != 'B' {
+		t.Errorf("Expected the S piece (B) to be placed first, got %c", first)
+	}
+}
+
+func TestLowerBounds(t *testing.T) {
+	testCases := []struct {
+		name  string
+		build func(t *testing.T) []*tetromino.Tetromino
+		size  int
+		bound string
+	}{
+		{name: "I on a 3x3 board", build: func(t *testing.T) []*tetromino.Tetromino {
+			return standardPieces(t, "IO")
+		}, size: 3, bound: solver.BoundPlacement},
+		{name: "four T on a 4x4 board", build: func(t *testing.T) []*tetromino.Tetromino {
+			return standardPieces(t, "TTTO")
+		}, size: 4, bound: solver.BoundCheckerboard},
+		{name: "upright L in a 4x4 square", build: func(t *testing.T) []*tetromino.Tetromino {
+			// Upright, the L covers three cells of one column colour and
+			// one of the other, but the O pieces cannot make up for it
+			pieces := standardPieces(t, "LOOO")
+			pieces[0].Rotations = []int{0, 2}
+			return pieces
+		}, size: 4, bound: solver.BoundColumns},
+		{name: "tight fit with a pinned piece", build: func(t *testing.T) []*tetromino.Tetromino {
+			pieces := standardPieces(t, "OOOO")
+			pieces[0].Pin = &tetromino.Pin{X: 0, Y: 0}
+			return pieces
+		}, size: 4, bound: ""},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := solver.SolveOptimalWithOptions(tc.build(t), solver.Options{})
+			if err != nil {
+				t.Fatalf("SolveOptimalWithOptions() error = %v", err)
+			}
+
+			var bound string
+			for _, proof := range result.Infeasible {
+				if proof.Size == tc.size {
+					bound = proof.Bound
+				}
+			}
+			if bound != tc.bound {
+				t.Errorf("Expected size %d ruled out by %q, got %+v", tc.size, tc.bound, result.Infeasible)
+			}
+
+			// A ruled-out size is never the answer
+			if tc.bound != "" && result.Success && result.Size <= tc.size {
+				t.Errorf("Expected a size above %d, got %d", tc.size, result.Size)
+			}
+		})
+	}
+}
+
+// TestLowerBoundsAreSound checks that no bound rules out a size where a
+// search finds a solution
+func TestLowerBoundsAreSound(t *testing.T) {
+	for _, letters := range []string{"TTTT", "TTTTTT", "IIII", "LJTSZ", "TTOO", "IITT", "TTTTO"} {
+		withBounds, err := solver.SolveOptimalWithOptions(standardPieces(t, letters), solver.Options{})
+		if err != nil {
+			t.Fatalf("SolveOptimalWithOptions() error = %v", err)
+		}
+		without, err := solver.SolveOptimalWithOptions(standardPieces(t, letters), solver.Options{SkipBounds: true})
+		if err != nil {
+			t.Fatalf("SolveOptimalWithOptions() error = %v", err)
+		}
+
+		if withBounds.Size != without.Size || withBounds.Success != without.Success {
+			t.Errorf("%s: expected size %d with bounds, got %d", letters, without.Size, withBounds.Size)
+		}
+		if withBounds.Nodes > without.Nodes {
+			t.Errorf("%s: expected bounds not to add nodes, got %d over %d", letters, withBounds.Nodes, without.Nodes)
+		}
+	}
+}
+
+func TestHeuristic(t *testing.T) {
+	testCases := []struct {
+		name    string
+		letters string
+		size    int
+		optimal bool
+	}{
+		// Reaching the area bound proves optimality
+		{name: "squares", letters: "OOOO", size: 4, optimal: true},
+		{name: "mixed pieces", letters: "IOTSZJL", size: 6, optimal: true},
+		// No lower bound rules out 5x5, so 6x6 stays unproven
+		{name: "repeated pieces", letters: "TTTTLL", size: 6, optimal: false},
+		{name: "large input", letters: strings.Repeat("IOTSZJL", 6), size: 14, optimal: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pieces := standardPieces(t, tc.letters)
+			result, err := solver.SolveOptimalWithOptions(pieces, solver.Options{
+				Strategy:  "heuristic",
+				TimeLimit: 200 * time.Millisecond,
+			})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if !result.Success || result.Size != tc.size {
+				t.Fatalf("Expected a %dx%d board, got success=%v size %d", tc.size, tc.size, result.Success, result.Size)
+			}
+			if result.Optimal != tc.optimal {
+				t.Errorf("Expected Optimal=%v, got %v", tc.optimal, result.Optimal)
+			}
+			if err := solver.Verify(pieces, result.Grid); err != nil {
+				t.Errorf("Expected a valid grid, got %v\n%s", err, result.Grid)
+			}
+		})
+	}
+}
+
+func TestCheckpointResume(t *testing.T) {
+	pieces := standardPieces(t, "TTTTLL")
+
+	var checkpoints []solver.Checkpoint
+	full, err := solver.SolveOptimalWithOptions(pieces, solver.Options{
+		CheckpointEvery: 500,
+		Checkpoint: func(c solver.Checkpoint) {
+			checkpoints = append(checkpoints, c)
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(checkpoints) < 2 {
+		t.Fatalf("Expected several checkpoints, got %d", len(checkpoints))
+	}
+
+	// Resume from checkpoints at the first size and the last one: the
+	// search must reach the same board with fewer new nodes
+	for _, c := range []solver.Checkpoint{checkpoints[0], checkpoints[len(checkpoints)/2], checkpoints[len(checkpoints)-1]} {
+		resumed, err := solver.SolveOptimalWithOptions(standardPieces(t, "TTTTLL"), solver.Options{Resume: &c})
+		if err != nil {
+			t.Fatalf("Expected no error resuming at size %d, got %v", c.Size, err)
+		}
+		if !resumed.Success || resumed.Grid.String() != full.Grid.String() {
+			t.Errorf("Expected resumed search to find\n%sgot\n%s", full.Grid, resumed.Grid)
+		}
+		if !resumed.Optimal {
+			t.Error("Expected resumed result to be optimal")
+		}
+		if added := resumed.Nodes - c.Nodes; added <= 0 || added >= full.Nodes {
+			t.Errorf("Expected resuming to explore fewer than %d new nodes, got %d", full.Nodes, added)
+		}
+	}
+}
+
+func TestCheckpointErrors(t *testing.T) {
+	pieces := standardPieces(t, "TOI")
+
+	testCases := []struct {
+		name string
+		opts solver.Options
+	}{
+		{name: "other strategy", opts: solver.Options{Strategy: "cell-first", Resume: &solver.Checkpoint{Size: 4}}},
+		{name: "dynamic order", opts: solver.Options{Order: solver.OrderDynamic, Checkpoint: func(solver.Checkpoint) {}}},
+		{name: "size out of range", opts: solver.Options{Resume: &solver.Checkpoint{Size: 2}}},
+		{name: "wrong piece", opts: solver.Options{Resume: &solver.Checkpoint{Size: 4, Stack: []solver.Choice{{Piece: "B"}}}}},
+		{name: "overlapping choices", opts: solver.Options{Resume: &solver.Checkpoint{Size: 4, Stack: []solver.Choice{
+			{Piece: "A", X: 0, Y: 0},
+			{Piece: "B", X: 0, Y: 0},
+		}}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := solver.SolveOptimalWithOptions(pieces, tc.opts); err == nil {
+				t.Error("Expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestSplitBranches(t *testing.T) {
+	pieces := standardPieces(t, "TTTTLL")
+
+	// 4x4 is too small for 24 cells
+	branches, proof, err := solver.Split(pieces, 4, 2, solver.Options{})
+	if err != nil || len(branches) != 0 || proof == nil || proof.Bound != solver.BoundArea {
+		t.Fatalf("Expected the area bound to rule out 4x4, got %d branches, %+v (%v)", len(branches), proof, err)
+	}
+
+	// Every branch at 5x5 fails; some branch at 6x6 succeeds
+	for size, want := range map[int]bool{5: false, 6: true} {
+		branches, _, err := solver.Split(pieces, size, 1, solver.Options{})
+		if err != nil {
+			t.Fatalf("Expected no error splitting %dx%d, got %v", size, size, err)
+		}
+		// A T piece has 4 orientations, each with (size-2)*(size-1) positions
+		if len(branches) != 4*(size-2)*(size-1) {
+			t.Errorf("Expected %d branches at %dx%d, got %d", 4*(size-2)*(size-1), size, size, len(branches))
+		}
+
+		found := false
+		for _, branch := range branches {
+			result, err := solver.SolveBranch(context.Background(), pieces, branch, solver.Options{})
+			if err != nil {
+				t.Fatalf("Expected no error solving a branch, got %v", err)
+			}
+			if result.Success {
+				found = true
+				if err := solver.Verify(pieces, result.Grid); err != nil {
+					t.Errorf("Expected a valid grid, got %v\n%s", err, result.Grid)
+				}
+				break
+			}
+		}
+		if found != want {
+			t.Errorf("Expected a solution at %dx%d: %v, got %v", size, size, want, found)
+		}
+	}
+}
+
+func TestCountSolutions(t *testing.T) {
+	ctx := context.Background()
+
+	testCases := []struct {
+		name      string
+		kinds     string
+		size      int
+		limit     int
+		solutions int
+		complete  bool
+	}{
+		{name: "one way", kinds: "OOOO", size: 4, solutions: 1, complete: true},
+		// Upright and flat rows of I are the same board turned
+		{name: "rotations are the same", kinds: "IIII", size: 4, solutions: 1, complete: true},
+		// The Is together or on opposite edges
+		{name: "two ways", kinds: "IIOO", size: 4, solutions: 2, complete: true},
+		{name: "limit", kinds: "IIOO", size: 4, limit: 1, solutions: 1},
+		{name: "no way", kinds: "TTTTLL", size: 5, solutions: 0, complete: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pieces := standardPieces(t, tc.kinds)
+
+			// The same shape given in another orientation is still identical
+			pieces[1].Rotate90()
+
+			count, err := solver.CountSolutions(ctx, pieces, tc.size, tc.limit, solver.Options{})
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if count.Solutions != tc.solutions || count.Complete != tc.complete {
+				t.Errorf("Expected %d solutions (complete %v), got %d (complete %v)", tc.solutions, tc.complete, count.Solutions, count.Complete)
+			}
+			if tc.solutions > 0 {
+				if err := solver.Verify(pieces, count.Grid); err != nil {
+					t.Errorf("Expected a valid first solution, got %v\n%s", err, count.Grid)
+				}
+			}
+		})
+	}
+
+	if _, err := solver.CountSolutions(ctx, nil, 4, 0, solver.Options{}); err == nil {
+		t.Error("Expected error for no pieces, got nil")
+	}
+}
+
+func TestComplete(t *testing.T) {
+	ctx := context.Background()
+	pieces := standardPieces(t, "OOOO")
+
+	testCases := []struct {
+		name    string
+		rows    []string
+		success bool
+		bound   string
+	}{
+		{name: "completable", rows: []string{"AA..", "AA..", "....", "...."}, success: true},
+		// The gap left of A can never be filled
+		{name: "dead end", rows: []string{".AA.", ".AA.", "....", "...."}, bound: solver.BoundSearch},
+		{name: "blocked cell", rows: []string{"AA..", "AA..", "...#", "...."}, bound: solver.BoundArea},
+	}
+
+	for _, tc := range testCases {
+		for _, strategy := range []string{"backtrack", "cell-first", "sat"} {
+			t.Run(tc.name+"/"+strategy, func(t *testing.T) {
+				g, err := grid.FromRows(tc.rows)
+				if err != nil {
+					t.Fatalf("Failed to build board: %v", err)
+				}
+
+				result, err := solver.Complete(ctx, g, pieces[1:], solver.Options{Strategy: strategy})
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				if strings.Join(tc.rows, "\n")+"\n" != g.String() {
+					t.Errorf("Expected the board to be left alone, got:\n%s", g)
+				}
+
+				if result.Success != tc.success {
+					t.Fatalf("Expected success %v, got %v", tc.success, result.Success)
+				}
+				if tc.success {
+					if err := solver.Verify(pieces, result.Grid); err != nil {
+						t.Errorf("Expected a valid completion, got %v\n%s", err, result.Grid)
+					}
+					if result.Grid.Cells[0][0] != 'A' {
+						t.Errorf("Expected A to stay in place, got:\n%s", result.Grid)
+					}
+					return
+				}
+				if len(result.Infeasible) != 1 || result.Infeasible[0].Bound != tc.bound {
+					t.Errorf("Expected a %s proof, got %+v", tc.bound, result.Infeasible)
+				}
+			})
+		}
+	}
+}
+
+func TestCompleteErrors(t *testing.T) {
+	ctx := context.Background()
+	pieces := standardPieces(t, "O")
+	g, err := grid.NewGrid(4)
+	if err != nil {
+		t.Fatalf("Failed to build board: %v", err)
+	}
+
+	if _, err := solver.Complete(ctx, nil, pieces, solver.Options{}); err == nil {
+		t.Error("Expected error for no board, got nil")
+	}
+	if _, err := solver.Complete(ctx, g, pieces, solver.Options{Size: 5}); err == nil {
+		t.Error("Expected error for a board size, got nil")
+	}
+	if _, err := solver.Complete(ctx, g, pieces, solver.Options{Strategy: "heuristic"}); err == nil {
+		t.Error("Expected error for the heuristic strategy, got nil")
+	}
+
+	// Nothing left to place is already complete
+	result, err := solver.Complete(ctx, g, nil, solver.Options{})
+	if err != nil || !result.Success {
+		t.Errorf("Expected success with no pieces, got %+v (%v)", result, err)
+	}
+}
@@ -0,0 +1,122 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/stkisengese/tetris-optimizer/internal/grid"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// Branch is one subtree of the search at a board size: the search with
+// the first pieces, in search order, fixed to the choices in Prefix.
+// The branches returned by Split for a size are disjoint and together
+// cover every packing at that size.
+type Branch struct {
+	Size   int
+	Prefix []Choice
+}
+
+// Split divides the search at size into branches, one for each way of
+// placing the first depth pieces in search order. A size that a lower
+// bound rules out, or where the prefix pieces cannot be placed, yields
+// no branches; the proof, if any, is returned alongside.
+func Split(tetrominoes []*tetromino.Tetromino, size, depth int, opts Options) ([]Branch, *Infeasible, error) {
+	g, pieces, proof, err := prepareBranch(tetrominoes, size, opts)
+	if err != nil || proof != nil {
+		return nil, proof, err
+	}
+
+	if depth > len(pieces) {
+		depth = len(pieces)
+	}
+
+	var branches []Branch
+	prefix := make([]Choice, 0, depth)
+	tables := newTables(g, pieces[:depth])
+
+	var expand func(index int)
+	expand = func(index int) {
+		if index == depth {
+			branches = append(branches, Branch{Size: size, Prefix: append([]Choice(nil), prefix...)})
+			return
+		}
+
+		placements := tables[index].placements
+		for i := range placements {
+			p := &placements[i]
+			if !p.fits(g) {
+				continue
+			}
+			if err := g.Place(p.Placement); err != nil {
+				continue
+			}
+			prefix = append(prefix, Choice{Piece: string(p.Piece), Orientation: p.orientation, X: p.X, Y: p.Y})
+			expand(index + 1)
+			prefix = prefix[:len(prefix)-1]
+			g.Remove(p.Placement)
+		}
+	}
+	expand(0)
+
+	return branches, nil, nil
+}
+
+// SolveBranch searches one branch from Split with the strategy named in
+// opts, which must be the options the branch was split with
+func SolveBranch(ctx context.Context, tetrominoes []*tetromino.Tetromino, branch Branch, opts Options) (*Result, error) {
+	strategy, err := Lookup(opts.Strategy)
+	if err != nil {
+		return nil, err
+	}
+	if err := CheckOrder(opts.Order); err != nil {
+		return nil, err
+	}
+	if _, ok := strategy.(anytimeSolver); ok {
+		return nil, fmt.Errorf("strategy %q cannot search a single branch", opts.Strategy)
+	}
+
+	g, pieces, proof, err := prepareBranch(tetrominoes, branch.Size, opts)
+	if err != nil {
+		return nil, err
+	}
+	if proof != nil {
+		return &Result{Grid: g, Size: g.Size, Infeasible: []Infeasible{*proof}}, nil
+	}
+
+	if err := checkResume(g, pieces, branch.Prefix); err != nil {
+		return nil, err
+	}
+	for depth, choice := range branch.Prefix {
+		piece := pieces[depth]
+		placement := grid.Placement{Piece: piece.ID, Orientation: piece.Orientations()[choice.Orientation], X: choice.X, Y: choice.Y}
+		if err := g.Place(placement); err != nil {
+			return nil, err
+		}
+	}
+
+	return strategy.Solve(ctx, pieces[len(branch.Prefix):], g, opts)
+}
+
+// prepareBranch builds the board for a size with masked cells blocked
+// and pinned pieces placed, checks the lower bounds, and returns the
+// remaining pieces in search order
+func prepareBranch(tetrominoes []*tetromino.Tetromino, size int, opts Options) (*grid.Grid, []*tetromino.Tetromino, *Infeasible, error) {
+	g, err := newBoard(size, opts.Mask)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	remaining, err := placePinned(g, tetrominoes)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if !opts.SkipBounds {
+		if proof := checkBounds(g, remaining); proof != nil {
+			return g, nil, proof, nil
+		}
+	}
+
+	return g, orderPieces(g, remaining, opts.Order), nil, nil
+}
@@ -0,0 +1,115 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/stkisengese/tetris-optimizer/internal/grid"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// DefaultStrategy is the strategy used when Options.Strategy is empty
+const DefaultStrategy = "backtrack"
+
+// Solver is a search algorithm that packs pieces onto a single board.
+// The board has already been prepared: masked cells are blocked and
+// pinned pieces placed, so pieces holds only what is left to place.
+// On success Result.Grid is the filled board. Solvers stop with
+// ctx.Err() once ctx is done and report progress through opts.
+type Solver interface {
+	Solve(ctx context.Context, pieces []*tetromino.Tetromino, board *grid.Grid, opts Options) (*Result, error)
+}
+
+// registry holds the named strategies
+var registry = struct {
+	sync.RWMutex
+	solvers map[string]Solver
+}{solvers: make(map[string]Solver)}
+
+func init() {
+	Register(DefaultStrategy, backtracker{})
+}
+
+// Register makes a strategy available under name, replacing any
+// strategy already registered with that name
+func Register(name string, s Solver) {
+	registry.Lock()
+	defer registry.Unlock()
+	registry.solvers[name] = s
+}
+
+// Lookup returns the strategy registered under name. An empty name
+// selects DefaultStrategy.
+func Lookup(name string) (Solver, error) {
+	if name == "" {
+		name = DefaultStrategy
+	}
+
+	registry.RLock()
+	defer registry.RUnlock()
+
+	s, ok := registry.solvers[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown strategy %q, expected one of: %s", name, strings.Join(strategyNames(), ", "))
+	}
+	return s, nil
+}
+
+// Strategies returns the registered strategy names in sorted order
+func Strategies() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+	return strategyNames()
+}
+
+// strategyNames lists the registry keys; the caller must hold the lock
+func strategyNames() []string {
+	names := make([]string, 0, len(registry.solvers))
+	for name := range registry.solvers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// backtracker is the "backtrack" strategy: it places pieces in the
+// given order, or most constrained first with OrderDynamic, trying every
+// orientation at every position
+type backtracker struct{}
+
+// Solve implements Solver
+func (backtracker) Solve(ctx context.Context, pieces []*tetromino.Tetromino, board *grid.Grid, opts Options) (*Result, error) {
+	s := newSearch(ctx, opts)
+	s.startSize(board)
+
+	tables := newTables(board, pieces)
+
+	var success bool
+	if opts.Order == OrderDynamic {
+		success = s.backtrackDynamic(board, tables, make([]bool, len(pieces)), 0)
+	} else {
+		if opts.Checkpoint != nil {
+			s.path = make([]Choice, len(pieces))
+		}
+		if opts.Resume != nil {
+			if err := checkResume(board, pieces, opts.Resume.Stack); err != nil {
+				return nil, err
+			}
+			s.resume = opts.Resume.Stack
+		}
+		success = s.backtrack(board, tables, 0)
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	return &Result{
+		Grid:    board,
+		Success: success,
+		Size:    board.Size,
+		Nodes:   s.nodes,
+	}, nil
+}
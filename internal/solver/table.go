@@ -0,0 +1,124 @@
+package solver
+
+import (
+	"github.com/stkisengese/tetris-optimizer/internal/grid"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// pieceTable is everything the search needs to know about one piece on
+// one board, worked out before the search starts: its distinct
+// orientations and every placement of them that lies on cells free in
+// the prepared board. Blocked cells and pieces placed before the search
+// never move, so placements touching them can be dropped up front.
+type pieceTable struct {
+	orientations []tetromino.Orientation
+
+	// placements are in the order the search tries them: by
+	// orientation, then row, then column
+	placements []tablePlacement
+
+	// anchored indexes the placements by anchor, the first cell they
+	// cover in row-major order: anchored[anchorStart[c]:anchorStart[c+1]]
+	// are those anchored on cell c, in table order
+	anchored    []int
+	anchorStart []int
+}
+
+// tablePlacement is one entry of a pieceTable
+type tablePlacement struct {
+	grid.Placement
+
+	// orientation indexes the table's orientations
+	orientation int
+
+	// cells are the board cells the placement covers
+	cells [4]tetromino.Point
+}
+
+// newTables builds the table of every piece for the board
+func newTables(board *grid.Grid, pieces []*tetromino.Tetromino) []pieceTable {
+	tables := make([]pieceTable, len(pieces))
+	for i, t := range pieces {
+		table := &tables[i]
+		table.orientations = t.Orientations()
+
+		capacity := 0
+		for _, o := range table.orientations {
+			capacity += max(0, board.Size-o.Width()+1) * max(0, board.Size-o.Height()+1)
+		}
+		table.placements = make([]tablePlacement, 0, capacity)
+
+		for r, o := range table.orientations {
+			for y := 0; y <= board.Size-o.Height(); y++ {
+				for x := 0; x <= board.Size-o.Width(); x++ {
+					if !board.CanPlace(o.Shape, x, y) {
+						continue
+					}
+					p := grid.Placement{Piece: t.ID, Orientation: o, X: x, Y: y}
+					table.placements = append(table.placements, tablePlacement{Placement: p, orientation: r, cells: p.Cells()})
+				}
+			}
+		}
+		table.indexAnchors(board.Size)
+	}
+	return tables
+}
+
+// indexAnchors groups the placements by anchor cell with a counting
+// sort, which keeps them in table order within each cell
+func (t *pieceTable) indexAnchors(size int) {
+	// Count each cell's placements at the cell after it, so the
+	// running sum leaves anchorStart[c+1] at the end of cell c
+	t.anchorStart = make([]int, size*size+1)
+	for i := range t.placements {
+		t.anchorStart[t.placements[i].anchor(size)+1]++
+	}
+	for c := 1; c < len(t.anchorStart); c++ {
+		t.anchorStart[c] += t.anchorStart[c-1]
+	}
+
+	// Filling from the back moves each end down to its cell's start
+	t.anchored = make([]int, len(t.placements))
+	for i := len(t.placements) - 1; i >= 0; i-- {
+		c := t.placements[i].anchor(size) + 1
+		t.anchorStart[c]--
+		t.anchored[t.anchorStart[c]] = i
+	}
+	copy(t.anchorStart, t.anchorStart[1:])
+	t.anchorStart[size*size] = len(t.placements)
+}
+
+// anchoredAt returns the indexes of the placements anchored on cell c
+func (t *pieceTable) anchoredAt(c int) []int {
+	return t.anchored[t.anchorStart[c]:t.anchorStart[c+1]]
+}
+
+// anchor returns the row-major index of the placement's first cell
+func (p *tablePlacement) anchor(size int) int {
+	return p.cells[0].Y*size + p.cells[0].X
+}
+
+// fits reports whether every cell of the placement is empty on g
+func (p *tablePlacement) fits(g *grid.Grid) bool {
+	for _, c := range p.cells {
+		if g.Cells[c.Y][c.X] != '.' {
+			return false
+		}
+	}
+	return true
+}
+
+// fitting counts the placements of the table that fit on g, stopping
+// early once the count reaches limit
+func (t *pieceTable) fitting(g *grid.Grid, limit int) int {
+	count := 0
+	for i := range t.placements {
+		if t.placements[i].fits(g) {
+			count++
+			if count >= limit {
+				break
+			}
+		}
+	}
+	return count
+}
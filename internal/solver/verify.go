@@ -0,0 +1,84 @@
+package solver
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/stkisengese/tetris-optimizer/internal/grid"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// Verify checks that g is a valid arrangement of the tetrominoes: every
+// piece's letter covers exactly one of its allowed orientations, pinned
+// pieces sit where they were pinned, and no other letters appear
+func Verify(tetrominoes []*tetromino.Tetromino, g *grid.Grid) error {
+	cells := make(map[rune][]tetromino.Point)
+	for y, row := range g.Cells {
+		for x, cell := range row {
+			if cell == '.' || cell == grid.Blocked {
+				continue
+			}
+			cells[cell] = append(cells[cell], tetromino.Point{X: x, Y: y})
+		}
+	}
+
+	for _, t := range tetrominoes {
+		points, ok := cells[t.ID]
+		if !ok {
+			return fmt.Errorf("piece %c is missing from the grid", t.ID)
+		}
+		delete(cells, t.ID)
+
+		if err := verifyPiece(t, points); err != nil {
+			return err
+		}
+	}
+
+	if len(cells) > 0 {
+		extra := make([]rune, 0, len(cells))
+		for id := range cells {
+			extra = append(extra, id)
+		}
+		sort.Slice(extra, func(i, j int) bool { return extra[i] < extra[j] })
+		return fmt.Errorf("grid contains unknown pieces %q", string(extra))
+	}
+
+	return nil
+}
+
+// verifyPiece checks that the cells covered by one letter form the
+// piece in an allowed orientation
+func verifyPiece(t *tetromino.Tetromino, points []tetromino.Point) error {
+	if len(points) != len(t.Shape.Cells()) {
+		return fmt.Errorf("piece %c covers %d cells, expected %d", t.ID, len(points), len(t.Shape.Cells()))
+	}
+
+	// Points arrive in row-major order, so the first one gives the
+	// minimum y; scan for the minimum x
+	origin := points[0]
+	for _, p := range points {
+		if p.X < origin.X {
+			origin.X = p.X
+		}
+	}
+
+	shape, err := tetromino.NewShape(points)
+	if err != nil {
+		return err
+	}
+
+	if t.Pin != nil {
+		if t.Orientation(t.Pin.Rotation).Shape != shape || origin.X != t.Pin.X || origin.Y != t.Pin.Y {
+			return fmt.Errorf("pinned piece %c has moved from (%d, %d)", t.ID, t.Pin.X, t.Pin.Y)
+		}
+		return nil
+	}
+
+	for _, rotation := range t.Orientations() {
+		if rotation.Shape == shape {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("piece %c at (%d, %d) does not match its shape", t.ID, origin.X, origin.Y)
+}
@@ -0,0 +1,126 @@
+package tetromino
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind identifies which of the seven standard tetrominoes a piece is
+type Kind int
+
+const (
+	// KindUnknown is used for shapes that match no standard tetromino
+	KindUnknown Kind = iota
+	KindI
+	KindO
+	KindT
+	KindS
+	KindZ
+	KindJ
+	KindL
+)
+
+// Kinds lists the standard tetromino kinds in a stable order
+var Kinds = []Kind{KindI, KindO, KindT, KindS, KindZ, KindJ, KindL}
+
+// kindLetters maps each kind to its conventional letter
+const kindLetters = "?IOTSZJL"
+
+// standardGrids holds a reference 4x4 drawing of each standard kind
+var standardGrids = map[Kind][]string{
+	KindI: {"####", "....", "....", "...."},
+	KindO: {"##..", "##..", "....", "...."},
+	KindT: {"###.", ".#..", "....", "...."},
+	KindS: {".##.", "##..", "....", "...."},
+	KindZ: {"##..", ".##.", "....", "...."},
+	KindJ: {".#..", ".#..", "##..", "...."},
+	KindL: {"#...", "#...", "##..", "...."},
+}
+
+// canonicalKinds maps the canonical shape key of each standard kind back
+// to the kind, so any orientation can be classified with one lookup
+var canonicalKinds = buildCanonicalKinds()
+
+func buildCanonicalKinds() map[string]Kind {
+	kinds := make(map[string]Kind, len(standardGrids))
+	for kind, rows := range standardGrids {
+		t, err := newFromGrid(0, rows)
+		if err != nil {
+			panic(fmt.Sprintf("invalid reference grid for %v: %v", kind, err))
+		}
+		kinds[t.CanonicalKey()] = kind
+	}
+	return kinds
+}
+
+// String returns the conventional letter for the kind
+func (k Kind) String() string {
+	if k < KindUnknown || int(k) >= len(kindLetters) {
+		return "?"
+	}
+	return kindLetters[k : k+1]
+}
+
+// MarshalText encodes the kind as its letter, so it reads naturally in
+// JSON output and as a map key
+func (k Kind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText decodes a kind from its letter
+func (k *Kind) UnmarshalText(text []byte) error {
+	kind, ok := ParseKind(string(text))
+	if !ok {
+		return fmt.Errorf("unknown tetromino kind %q", text)
+	}
+	*k = kind
+	return nil
+}
+
+// ParseKind returns the kind named by a single letter such as "T".
+// Lowercase letters are accepted.
+func ParseKind(name string) (Kind, bool) {
+	if len(name) != 1 {
+		return KindUnknown, false
+	}
+
+	idx := strings.IndexByte(kindLetters, strings.ToUpper(name)[0])
+	if idx <= int(KindUnknown) {
+		return KindUnknown, false
+	}
+	return Kind(idx), true
+}
+
+// Classify reports which standard kind the tetromino is, regardless of
+// its current orientation
+func Classify(t *Tetromino) Kind {
+	if kind, ok := canonicalKinds[t.CanonicalKey()]; ok {
+		return kind
+	}
+	return KindUnknown
+}
+
+// NewStandard creates a standard tetromino of the given kind in its
+// reference orientation
+func NewStandard(id rune, kind Kind) (*Tetromino, error) {
+	rows, ok := standardGrids[kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown tetromino kind %v", kind)
+	}
+	return NewTetromino(id, rows)
+}
+
+// CanonicalKey returns a shape key that is the same for every rotation
+// of the tetromino
+func (t *Tetromino) CanonicalKey() string {
+	return t.Shape.CanonicalKey()
+}
+
+// CountKinds tallies how many pieces of each kind are present
+func CountKinds(tetrominoes []*Tetromino) map[Kind]int {
+	counts := make(map[Kind]int)
+	for _, t := range tetrominoes {
+		counts[t.Kind]++
+	}
+	return counts
+}
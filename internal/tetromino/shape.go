@@ -0,0 +1,113 @@
+package tetromino
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Shape is the four cells of a tetromino in one orientation, shifted so
+// its bounding box starts at (0, 0). A Shape is an immutable value:
+// rotating it returns a new Shape, so it can be shared freely, also
+// between goroutines, and compared with ==.
+type Shape struct {
+	cells  [4]Point
+	width  int
+	height int
+}
+
+// NewShape builds a shape from four cells, normalising them to the
+// origin
+func NewShape(points []Point) (Shape, error) {
+	if len(points) != 4 {
+		return Shape{}, fmt.Errorf("tetromino must have exactly 4 blocks, got %d", len(points))
+	}
+
+	var cells [4]Point
+	copy(cells[:], points)
+	return normalize(cells), nil
+}
+
+// normalize shifts cells so the minimum x and y are 0, sorts them in
+// row-major order and measures the bounding box
+func normalize(cells [4]Point) Shape {
+	minX, minY := cells[0].X, cells[0].Y
+	maxX, maxY := minX, minY
+	for _, p := range cells[1:] {
+		minX, maxX = min(minX, p.X), max(maxX, p.X)
+		minY, maxY = min(minY, p.Y), max(maxY, p.Y)
+	}
+
+	for i := range cells {
+		cells[i] = Point{X: cells[i].X - minX, Y: cells[i].Y - minY}
+	}
+	sort.Slice(cells[:], func(i, j int) bool {
+		if cells[i].Y == cells[j].Y {
+			return cells[i].X < cells[j].X
+		}
+		return cells[i].Y < cells[j].Y
+	})
+
+	return Shape{cells: cells, width: maxX - minX + 1, height: maxY - minY + 1}
+}
+
+// Cells returns the cells of the shape in row-major order, so the first
+// is the top-most, then left-most
+func (s Shape) Cells() [4]Point {
+	return s.cells
+}
+
+// Width returns the width of the bounding box
+func (s Shape) Width() int {
+	return s.width
+}
+
+// Height returns the height of the bounding box
+func (s Shape) Height() int {
+	return s.height
+}
+
+// Rotate90 returns the shape turned 90 degrees clockwise
+func (s Shape) Rotate90() Shape {
+	var cells [4]Point
+	for i, p := range s.cells {
+		// Rotation formula: (x, y) -> (y, -x), then normalised
+		cells[i] = Point{X: p.Y, Y: -p.X}
+	}
+	return normalize(cells)
+}
+
+// Key returns a string that identifies the shape in this orientation
+func (s Shape) Key() string {
+	var builder strings.Builder
+	for i, p := range s.cells {
+		if i > 0 {
+			builder.WriteString(",")
+		}
+		builder.WriteString(strconv.Itoa(p.X))
+		builder.WriteString(":")
+		builder.WriteString(strconv.Itoa(p.Y))
+	}
+	return builder.String()
+}
+
+// CanonicalKey returns a key that is the same for every rotation of the
+// shape
+func (s Shape) CanonicalKey() string {
+	best := s.Key()
+	for i := 1; i < 4; i++ {
+		s = s.Rotate90()
+		if key := s.Key(); key < best {
+			best = key
+		}
+	}
+	return best
+}
+
+// Orientation is a piece's shape after Turns clockwise quarter turns
+// from the orientation it was given in
+type Orientation struct {
+	Shape
+	Turns int
+}
@@ -2,8 +2,7 @@ package tetromino
 
 import (
 	"fmt"
-	"sort"
-	"strings"
+	"slices"
 )
 
 // Point represents a coordinate in 2D space
@@ -17,32 +16,57 @@ func (p Point) Add(other Point) Point {
 	return Point{X: p.X + other.X, Y: p.Y + other.Y}
 }
 
-// Tetromino represents a tetris piece with its shape and position
+// Tetromino is a piece of a puzzle: its identity, its shape as given
+// and any restrictions on how it may be placed. Where a piece sits on a
+// board is recorded by the board (see grid.Placement), not here.
 type Tetromino struct {
 	// ID is the identifier for this tetromino (A, B, C, etc.)
 	ID rune
 
-	// Points contains the relative coordinates of the tetromino blocks
-	// All coordinates are relative to the top-left corner (0,0)
-	Points []Point
+	// Shape is the piece in the orientation it was given in
+	Shape Shape
 
-	// Width and Height represent the bounding box dimensions
-	Width  int
-	Height int
+	// Kind is the standard tetromino this shape matches, if any
+	Kind Kind
 
-	// Position represents the current position on the grid
-	Position Point
+	// Rotations optionally restricts the clockwise quarter turns (0-3),
+	// relative to the input orientation, the solver may use. Empty
+	// allows every orientation.
+	Rotations []int
+
+	// Pin, when set, fixes the piece on the board before the search
+	Pin *Pin
+}
+
+// Pin fixes a piece at a board position and orientation
+type Pin struct {
+	// X and Y locate the top-left corner of the rotated bounding box
+	X int
+	Y int
+
+	// Rotation is the number of clockwise quarter turns applied to the
+	// piece as given
+	Rotation int
 }
 
 // NewTetromino creates a new tetromino from a 4x4 grid representation
 func NewTetromino(id rune, grid []string) (*Tetromino, error) {
+	t, err := newFromGrid(id, grid)
+	if err != nil {
+		return nil, err
+	}
+
+	t.Kind = Classify(t)
+	return t, nil
+}
+
+// newFromGrid builds the tetromino geometry without classifying it
+func newFromGrid(id rune, grid []string) (*Tetromino, error) {
 	if len(grid) != 4 {
 		return nil, fmt.Errorf("tetromino grid must be 4x4, got %d rows", len(grid))
 	}
 
 	var points []Point
-	minX, minY := 4, 4
-	maxX, maxY := -1, -1
 
 	// Parse the grid and find all '#' positions
 	for y, row := range grid {
@@ -53,149 +77,77 @@ func NewTetromino(id rune, grid []string) (*Tetromino, error) {
 		for x, char := range row {
 			if char == '#' {
 				points = append(points, Point{X: x, Y: y})
-				if x < minX {
-					minX = x
-				}
-				if x > maxX {
-					maxX = x
-				}
-				if y < minY {
-					minY = y
-				}
-				if y > maxY {
-					maxY = y
-				}
 			}
 		}
 	}
 
-	if len(points) != 4 {
-		return nil, fmt.Errorf("tetromino must have exactly 4 blocks, got %d", len(points))
+	shape, err := NewShape(points)
+	if err != nil {
+		return nil, err
 	}
-
-	// Normalize coordinates to start from (0,0)
-	normalizedPoints := make([]Point, len(points))
-	for i, p := range points {
-		normalizedPoints[i] = Point{X: p.X - minX, Y: p.Y - minY}
-	}
-
-	return &Tetromino{
-		ID:       id,
-		Points:   normalizedPoints,
-		Width:    maxX - minX + 1,
-		Height:   maxY - minY + 1,
-		Position: Point{X: 0, Y: 0},
-	}, nil
+	return &Tetromino{ID: id, Shape: shape}, nil
 }
 
-// Copy creates a deep copy of the tetromino
+// Clone creates a deep copy of the tetromino
 func (t *Tetromino) Clone() *Tetromino {
-	points := make([]Point, len(t.Points))
-	copy(points, t.Points)
-
-	return &Tetromino{
-		ID:       t.ID,
-		Points:   points,
-		Width:    t.Width,
-		Height:   t.Height,
-		Position: t.Position,
+	var rotations []int
+	if t.Rotations != nil {
+		rotations = make([]int, len(t.Rotations))
+		copy(rotations, t.Rotations)
 	}
-}
 
-// SetPosition updates the tetromino's position on the grid
-func (t *Tetromino) SetPosition(x, y int) {
-	t.Position = Point{X: x, Y: y}
-}
+	var pin *Pin
+	if t.Pin != nil {
+		p := *t.Pin
+		pin = &p
+	}
 
-// GetAbsolutePoints returns the absolute positions of all blocks
-func (t *Tetromino) GetAbsolutePoints() []Point {
-	result := make([]Point, len(t.Points))
-	for i, p := range t.Points {
-		result[i] = p.Add(t.Position)
+	return &Tetromino{
+		ID:        t.ID,
+		Shape:     t.Shape,
+		Kind:      t.Kind,
+		Rotations: rotations,
+		Pin:       pin,
 	}
-	return result
 }
 
-// Rotate90 rotates the tetromino 90 degrees clockwise
+// Rotate90 turns the piece's given orientation 90 degrees clockwise
 func (t *Tetromino) Rotate90() {
-	newPoints := make([]Point, len(t.Points))
-
-	for i, p := range t.Points {
-		// Rotation formula: (x, y) -> (y, -x)
-		// But we need to adjust for the new bounding box
-		newPoints[i] = Point{X: p.Y, Y: -p.X}
-	}
-
-	// Normalize the rotated points
-	t.Points = t.normalizePoints(newPoints)
-
-	// Swap width and height
-	t.Width, t.Height = t.Height, t.Width
+	t.Shape = t.Shape.Rotate90()
 }
 
-// GenerateRotations generates all unique rotations of the tetromino
-func (t *Tetromino) GenerateRotations() []*Tetromino {
-	rotations := make([]*Tetromino, 0, 4)
-	current := t.Clone()
-
-	seen := make(map[string]bool)
-
-	for i := 0; i < 4; i++ {
-		key := current.ShapeKey()
-		if !seen[key] {
-			rotations = append(rotations, current.Clone())
-			seen[key] = true
-		}
-		current.Rotate90()
+// Orientation returns the piece turned clockwise by the given number of
+// quarter turns
+func (t *Tetromino) Orientation(turns int) Orientation {
+	turns = ((turns % 4) + 4) % 4
+	shape := t.Shape
+	for i := 0; i < turns; i++ {
+		shape = shape.Rotate90()
 	}
-
-	return rotations
+	return Orientation{Shape: shape, Turns: turns}
 }
 
-// normalizePoints adjusts points so the minimum x and y are 0
-func (t *Tetromino) normalizePoints(points []Point) []Point {
-	if len(points) == 0 {
-		return points
+// Orientations returns the distinct orientations permitted by
+// Rotations, or every distinct orientation when Rotations is empty
+func (t *Tetromino) Orientations() []Orientation {
+	turns := t.Rotations
+	if len(turns) == 0 {
+		turns = []int{0, 1, 2, 3}
 	}
 
-	minX, minY := points[0].X, points[0].Y
-	for _, p := range points {
-		if p.X < minX {
-			minX = p.X
+	orientations := make([]Orientation, 0, len(turns))
+	for _, n := range turns {
+		o := t.Orientation(n)
+		if !slices.ContainsFunc(orientations, func(seen Orientation) bool { return seen.Shape == o.Shape }) {
+			orientations = append(orientations, o)
 		}
-		if p.Y < minY {
-			minY = p.Y
-		}
-	}
-
-	normalized := make([]Point, len(points))
-	for i, p := range points {
-		normalized[i] = Point{X: p.X - minX, Y: p.Y - minY}
 	}
 
-	return normalized
+	return orientations
 }
 
-// shapeKey generates a unique string key for the tetromino shape
+// ShapeKey returns a string that identifies the piece's shape in its
+// given orientation
 func (t *Tetromino) ShapeKey() string {
-	// Sort points to ensure consistent ordering
-	points := make([]Point, len(t.Points))
-	copy(points, t.Points)
-
-	sort.Slice(points, func(i, j int) bool {
-		if points[i].Y == points[j].Y {
-			return points[i].X < points[j].X
-		}
-		return points[i].Y < points[j].Y
-	})
-
-	var builder strings.Builder
-	for i, p := range points {
-		if i > 0 {
-			builder.WriteString(",")
-		}
-		builder.WriteString(fmt.Sprintf("%d:%d", p.X, p.Y))
-	}
-
-	return builder.String()
+	return t.Shape.Key()
 }
@@ -1,6 +1,7 @@
 package tetromino_test
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
@@ -24,12 +25,8 @@ func TestNewTetromino(t *testing.T) {
 		t.Errorf("Expected ID 'L', got %c", tetro.ID)
 	}
 
-	if len(tetro.Points) != 4 {
-		t.Errorf("Expected 4 points, got %d", len(tetro.Points))
-	}
-
-	if tetro.Width != 2 || tetro.Height != 3 {
-		t.Errorf("Expected dimensions 2x3, got %dx%d", tetro.Width, tetro.Height)
+	if tetro.Shape.Width() != 2 || tetro.Shape.Height() != 3 {
+		t.Errorf("Expected dimensions 2x3, got %dx%d", tetro.Shape.Width(), tetro.Shape.Height())
 	}
 
 	// Test invalid grid (wrong number of blocks)
@@ -60,24 +57,26 @@ func TestTetrominoRotation(t *testing.T) {
 		t.Fatalf("Expected no error, got %v", err)
 	}
 
-	originalWidth := tetro.Width
-	originalHeight := tetro.Height
+	original := tetro.Shape
 
 	// Rotate 90 degrees
 	tetro.Rotate90()
 
 	// Check dimensions swapped
-	if tetro.Width != originalHeight || tetro.Height != originalWidth {
+	if tetro.Shape.Width() != original.Height() || tetro.Shape.Height() != original.Width() {
 		t.Errorf("Expected dimensions to swap after rotation")
 	}
 
-	// Should still have 4 points
-	if len(tetro.Points) != 4 {
-		t.Errorf("Expected 4 points after rotation, got %d", len(tetro.Points))
+	// Four quarter turns come back to the start
+	for i := 0; i < 3; i++ {
+		tetro.Rotate90()
+	}
+	if tetro.Shape != original {
+		t.Errorf("Expected four rotations to restore the shape, got %s", tetro.ShapeKey())
 	}
 }
 
-func TestGenerateRotations(t *testing.T) {
+func TestOrientations(t *testing.T) {
 	// Test I-piece (should have 2 unique rotations)
 	grid := []string{
 		"....",
@@ -91,7 +90,7 @@ func TestGenerateRotations(t *testing.T) {
 		t.Fatalf("Failed to create tetromino: %v", err)
 	}
 
-	rotations := tetro.GenerateRotations()
+	rotations := tetro.Orientations()
 
 	// I-piece should have 2 unique rotations (horizontal and vertical)
 	if len(rotations) != 2 {
@@ -111,7 +110,7 @@ func TestGenerateRotations(t *testing.T) {
 		t.Fatalf("Failed to create tetromino: %v", err)
 	}
 
-	rotations = tetro.GenerateRotations()
+	rotations = tetro.Orientations()
 
 	// O-piece should have 1 unique rotation (it's symmetric)
 	if len(rotations) != 1 {
@@ -131,7 +130,7 @@ func TestGenerateRotations(t *testing.T) {
 		t.Fatalf("Failed to create tetromino: %v", err)
 	}
 
-	rotations = tetro.GenerateRotations()
+	rotations = tetro.Orientations()
 
 	// L-piece should have 4 unique rotations
 	if len(rotations) != 4 {
@@ -188,57 +187,155 @@ func TestCloneAlias(t *testing.T) {
 		t.Fatalf("Failed to create tetromino: %v", err)
 	}
 
-	// Test that Clone() is an alias for Copy()
-	cloned := tetro.Clone()
-
 	// Test that it's a deep copy
-	cloned.SetPosition(5, 5)
-	if tetro.Position.X != 0 || tetro.Position.Y != 0 {
+	cloned := tetro.Clone()
+	cloned.Rotate90()
+	cloned.Rotations = append(cloned.Rotations, 1)
+	if tetro.ShapeKey() == cloned.ShapeKey() || len(tetro.Rotations) != 0 {
 		t.Error("Expected original tetromino to be unchanged after cloning")
 	}
 }
 
-func TestGetAbsolutePoints(t *testing.T) {
-	// Test L-shaped tetromino
-	grid := []string{
-		"#...",
-		"#...",
-		"##..",
-		"....",
-	}
-
-	tetro, err := tetromino.NewTetromino('L', grid)
+func TestShape(t *testing.T) {
+	shape, err := tetromino.NewShape([]tetromino.Point{{X: 5, Y: 3}, {X: 3, Y: 4}, {X: 4, Y: 4}, {X: 5, Y: 4}})
 	if err != nil {
 		t.Fatalf("Expected no error, got %v", err)
 	}
 
-	// Test with default position (0, 0)
-	absolutePoints := tetro.GetAbsolutePoints()
+	// Cells are shifted to the origin and sorted in row-major order
+	expected := [4]tetromino.Point{{X: 2, Y: 0}, {X: 0, Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 1}}
+	if shape.Cells() != expected {
+		t.Errorf("Expected cells %v, got %v", expected, shape.Cells())
+	}
+	if shape.Width() != 3 || shape.Height() != 2 {
+		t.Errorf("Expected dimensions 3x2, got %dx%d", shape.Width(), shape.Height())
+	}
+
+	// Shapes are values: rotating or editing a copy of the cells leaves
+	// the shape alone
+	cells := shape.Cells()
+	cells[0] = tetromino.Point{X: 9, Y: 9}
+	rotated := shape.Rotate90()
+	if shape.Cells() != expected || rotated == shape {
+		t.Errorf("Expected the shape to be unchanged, got %v", shape.Cells())
+	}
+	if rotated.CanonicalKey() != shape.CanonicalKey() {
+		t.Error("Expected rotations to share a canonical key")
+	}
+
+	if _, err := tetromino.NewShape(expected[:3]); err == nil {
+		t.Error("Expected error for a shape with 3 cells")
+	}
+}
 
-	if len(absolutePoints) != 4 {
-		t.Errorf("Expected 4 absolute points, got %d", len(absolutePoints))
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		grid     []string
+		expected tetromino.Kind
+	}{
+		{name: "I-piece", grid: []string{"....", "####", "....", "...."}, expected: tetromino.KindI},
+		{name: "O-piece", grid: []string{"....", ".##.", ".##.", "...."}, expected: tetromino.KindO},
+		{name: "T-piece", grid: []string{"....", ".###", "..#.", "...."}, expected: tetromino.KindT},
+		{name: "S-piece", grid: []string{"....", "..##", ".##.", "...."}, expected: tetromino.KindS},
+		{name: "Z-piece", grid: []string{"....", ".##.", "..##", "...."}, expected: tetromino.KindZ},
+		{name: "J-piece", grid: []string{"....", ".#..", ".###", "...."}, expected: tetromino.KindJ},
+		{name: "L-piece", grid: []string{"....", "...#", ".###", "...."}, expected: tetromino.KindL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tetro, err := tetromino.NewTetromino('A', tt.grid)
+			if err != nil {
+				t.Fatalf("Failed to create tetromino: %v", err)
+			}
+
+			// Classification must not depend on orientation
+			for i := 0; i < 4; i++ {
+				if kind := tetromino.Classify(tetro); kind != tt.expected {
+					t.Errorf("Rotation %d: expected kind %v, got %v", i, tt.expected, kind)
+				}
+				tetro.Rotate90()
+			}
+
+			if tetro.Kind != tt.expected {
+				t.Errorf("Expected Kind field %v, got %v", tt.expected, tetro.Kind)
+			}
+		})
 	}
+}
+
+func TestParseKind(t *testing.T) {
+	for _, kind := range tetromino.Kinds {
+		parsed, ok := tetromino.ParseKind(kind.String())
+		if !ok || parsed != kind {
+			t.Errorf("Expected ParseKind(%q) = %v, got %v (ok=%v)", kind.String(), kind, parsed, ok)
+		}
 
-	// With position (0, 0), absolute points should equal relative points
-	for i, absPoint := range absolutePoints {
-		expectedPoint := tetro.Points[i]
-		if absPoint.X != expectedPoint.X || absPoint.Y != expectedPoint.Y {
-			t.Errorf("Expected absolute point %d to be (%d, %d), got (%d, %d)",
-				i, expectedPoint.X, expectedPoint.Y, absPoint.X, absPoint.Y)
+		standard, err := tetromino.NewStandard('A', kind)
+		if err != nil {
+			t.Fatalf("Failed to create standard %v: %v", kind, err)
+		}
+		if standard.Kind != kind {
+			t.Errorf("Expected standard piece to be %v, got %v", kind, standard.Kind)
 		}
 	}
 
-	// Test with offset position
-	tetro.SetPosition(3, 2)
-	absolutePoints = tetro.GetAbsolutePoints()
+	if _, ok := tetromino.ParseKind("t"); !ok {
+		t.Error("Expected lowercase letters to be accepted")
+	}
 
-	// Verify all points are offset by (3, 2)
-	for i, absPoint := range absolutePoints {
-		expectedX := tetro.Points[i].X + 3
-		expectedY := tetro.Points[i].Y + 2
-		if absPoint.X != expectedX || absPoint.Y != expectedY {
-			t.Errorf("Expected absolute point %d to be (%d, %d), got (%d, %d)",
-				i, expectedX, expectedY, absPoint.X, absPoint.Y)
+	for _, name := range []string{"", "?", "Q", "TT"} {
+		if _, ok := tetromino.ParseKind(name); ok {
+			t.Errorf("Expected ParseKind(%q) to fail", name)
 		}
 	}
 }
+
+func TestKindJSON(t *testing.T) {
+	data, err := json.Marshal(map[tetromino.Kind]int{tetromino.KindT: 2})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if string(data) != `{"T":2}` {
+		t.Errorf("Expected kinds to encode by letter, got %s", data)
+	}
+
+	var kind tetromino.Kind
+	if err := json.Unmarshal([]byte(`"S"`), &kind); err != nil || kind != tetromino.KindS {
+		t.Errorf("Expected to decode S, got %v (err=%v)", kind, err)
+	}
+}
+
+func TestRestrictedOrientations(t *testing.T) {
+	tetro, err := tetromino.NewStandard('A', tetromino.KindL)
+	if err != nil {
+		t.Fatalf("Failed to create tetromino: %v", err)
+	}
+
+	if n := len(tetro.Orientations()); n != 4 {
+		t.Errorf("Expected 4 orientations without restrictions, got %d", n)
+	}
+
+	tetro.Rotations = []int{0, 2}
+	orientations := tetro.Orientations()
+	if len(orientations) != 2 {
+		t.Fatalf("Expected 2 orientations, got %d", len(orientations))
+	}
+
+	upsideDown := tetro.Shape.Rotate90().Rotate90()
+	if orientations[1].Shape != upsideDown || orientations[1].Turns != 2 {
+		t.Errorf("Expected second orientation to be rotated 180 degrees, got %+v", orientations[1])
+	}
+	if tetro.Orientation(6) != orientations[1] {
+		t.Error("Expected turns to wrap around at 4")
+	}
+
+	// Symmetric turns of an O piece collapse to one orientation
+	square, _ := tetromino.NewStandard('B', tetromino.KindO)
+	square.Rotations = []int{0, 1, 2, 3}
+	if n := len(square.Orientations()); n != 1 {
+		t.Errorf("Expected 1 orientation for O piece, got %d", n)
+	}
+}
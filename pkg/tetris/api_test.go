@@ -0,0 +1,122 @@
+package tetris_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/stkisengese/tetris-optimizer/pkg/tetris"
+)
+
+// These assignments pin the exported signatures; changing any of them
+// breaks callers and fails to compile here.
+var (
+	_ func(io.Reader, tetris.Format) (*tetris.Puzzle, error)                          = tetris.Parse
+	_ func(context.Context, *tetris.Puzzle, tetris.Options) (*tetris.Solution, error) = tetris.Solve
+	_ func(*tetris.Puzzle, []string) error                                            = tetris.Verify
+	_ func(*tetris.Solution) string                                                   = tetris.Render
+
+	_       = []tetris.Format{tetris.FormatAuto, tetris.FormatBlocks, tetris.FormatCompact, tetris.FormatJSON}
+	_ error = tetris.ErrNoSolution
+
+	_ = tetris.Piece{ID: "", Kind: "", Cells: []tetris.Point{{X: 0, Y: 0}}, Orientations: []int{}, Pin: &tetris.Pin{X: 0, Y: 0, Orientation: 0}}
+	_ = tetris.Board{Size: 0, MaxSize: 0, Mask: []string{}}
+	_ = tetris.Puzzle{Pieces: []tetris.Piece{}, Board: tetris.Board{}}
+	_ = tetris.Placement{ID: "", Cells: []tetris.Point{}}
+	_ = tetris.Solution{Size: 0, Grid: []string{}, Placements: []tetris.Placement{}, Nodes: 0, Optimal: false}
+	_ = tetris.Options{Progress: func(tetris.Progress) {}, ProgressEvery: 0, Strategy: "", Order: "", TimeLimit: 0}
+	_ = tetris.Progress{Size: 0, Nodes: 0, Depth: 0}
+)
+
+// TestFormatValues pins the format names, which callers may store
+func TestFormatValues(t *testing.T) {
+	formats := map[tetris.Format]string{
+		tetris.FormatAuto:    "auto",
+		tetris.FormatBlocks:  "blocks",
+		tetris.FormatCompact: "compact",
+		tetris.FormatJSON:    "json",
+	}
+	for format, name := range formats {
+		if string(format) != name {
+			t.Errorf("Expected format %q, got %q", name, format)
+		}
+	}
+}
+
+// TestJSONEncoding pins the wire form of the public types
+func TestJSONEncoding(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    any
+		expected string
+	}{
+		{
+			name: "puzzle",
+			value: tetris.Puzzle{
+				Pieces: []tetris.Piece{
+					{ID: "A", Kind: "T", Orientations: []int{0, 90}},
+					{Cells: []tetris.Point{{0, 0}, {1, 0}, {2, 0}, {3, 0}}, Pin: &tetris.Pin{X: 1, Y: 2, Orientation: 90}},
+				},
+				Board: tetris.Board{Size: 5, MaxSize: 6, Mask: []string{"#...."}},
+			},
+			expected: `{"pieces":[{"id":"A","kind":"T","orientations":[0,90]},{"cells":[[0,0],[1,0],[2,0],[3,0]],"pin":{"x":1,"y":2,"orientation":90}}],"board":{"size":5,"mask":["#...."]},"options":{"max_size":6}}`,
+		},
+		{
+			name: "solution",
+			value: tetris.Solution{
+				Size:       2,
+				Grid:       []string{"AA", "AA"},
+				Placements: []tetris.Placement{{ID: "A", Cells: []tetris.Point{{0, 0}, {1, 0}, {0, 1}, {1, 1}}}},
+				Nodes:      1,
+				Optimal:    true,
+			},
+			expected: `{"size":2,"grid":["AA","AA"],"placements":[{"id":"A","cells":[[0,0],[1,0],[0,1],[1,1]]}],"nodes":1,"optimal":true}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			data, err := json.Marshal(tc.value)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if string(data) != tc.expected {
+				t.Errorf("Expected:\n%s\nGot:\n%s", tc.expected, data)
+			}
+		})
+	}
+
+	var puzzle tetris.Puzzle
+	if err := json.Unmarshal([]byte(`{"pieces":[{"cells":[[0,0],[1,0],[2,0],[3,0]]}],"options":{"max_size":7}}`), &puzzle); err != nil {
+		t.Fatalf("Expected no error decoding, got %v", err)
+	}
+	if puzzle.Pieces[0].Cells[3] != (tetris.Point{X: 3, Y: 0}) {
+		t.Errorf("Expected decoded cell (3, 0), got %+v", puzzle.Pieces[0].Cells[3])
+	}
+	if puzzle.Board.MaxSize != 7 {
+		t.Errorf("Expected decoded max size 7, got %d", puzzle.Board.MaxSize)
+	}
+}
+
+// TestMarshalParses feeds a marshaled puzzle back through the JSON parser
+func TestMarshalParses(t *testing.T) {
+	puzzle := tetris.Puzzle{
+		Pieces: []tetris.Piece{{ID: "A", Kind: "I"}, {ID: "B", Kind: "O", Pin: &tetris.Pin{X: 0, Y: 1}}},
+		Board:  tetris.Board{MaxSize: 5, Mask: []string{"....", "....", "....", "...#"}},
+	}
+	data, err := json.Marshal(puzzle)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	parsed, err := tetris.Parse(bytes.NewReader(data), tetris.FormatJSON)
+	if err != nil {
+		t.Fatalf("Expected %s to parse, got %v", data, err)
+	}
+	if !reflect.DeepEqual(*parsed, puzzle) {
+		t.Errorf("Expected %+v, got %+v", puzzle, *parsed)
+	}
+}
@@ -0,0 +1,400 @@
+// Package tetris is the public API of the optimizer. It parses puzzles
+// in any supported input format, solves them on the smallest possible
+// square board, verifies proposed solutions and renders them as text.
+//
+// The types mirror the JSON input format, so a Puzzle marshals to a
+// document the command-line tool accepts:
+//
+//	puzzle := &tetris.Puzzle{Pieces: []tetris.Piece{{Kind: "I"}, {Kind: "O"}}}
+//	solution, err := tetris.Solve(ctx, puzzle, tetris.Options{})
+//	if err != nil {
+//		return err
+//	}
+//	fmt.Print(tetris.Render(solution))
+package tetris
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/stkisengese/tetris-optimizer/internal/grid"
+	"github.com/stkisengese/tetris-optimizer/internal/parser"
+	"github.com/stkisengese/tetris-optimizer/internal/solver"
+	"github.com/stkisengese/tetris-optimizer/internal/tetromino"
+)
+
+// Format selects the syntax of text passed to Parse
+type Format string
+
+const (
+	// FormatAuto detects the format from the input
+	FormatAuto Format = "auto"
+
+	// FormatBlocks is the 4x4 block format, one drawing per piece
+	FormatBlocks Format = "blocks"
+
+	// FormatCompact is the piece-name format, e.g. "I O T x2"
+	FormatCompact Format = "compact"
+
+	// FormatJSON is the structured JSON format
+	FormatJSON Format = "json"
+)
+
+// ErrNoSolution is returned by Solve when no board up to the maximum
+// size fits every piece
+var ErrNoSolution = errors.New("no solution found")
+
+// Point is a cell coordinate. It encodes in JSON as an [x, y] pair.
+type Point struct {
+	X int
+	Y int
+}
+
+// Piece is one tetromino of a puzzle. Exactly one of Kind or Cells
+// describes its shape.
+type Piece struct {
+	// ID labels the piece in the solution grid. Empty IDs are assigned
+	// 'A', 'B', ... in order, skipping IDs other pieces use.
+	ID string `json:"id,omitempty"`
+
+	// Kind names a standard tetromino: I, O, T, S, Z, J or L
+	Kind string `json:"kind,omitempty"`
+
+	// Cells lists the four cells of a custom or rotated shape
+	Cells []Point `json:"cells,omitempty"`
+
+	// Orientations restricts the clockwise rotations, in degrees, the
+	// solver may use. Empty allows all of them.
+	Orientations []int `json:"orientations,omitempty"`
+
+	// Pin fixes the piece on the board before the search
+	Pin *Pin `json:"pin,omitempty"`
+}
+
+// Pin places a piece with the top-left corner of its rotated bounding
+// box at X, Y, turned clockwise by Orientation degrees
+type Pin struct {
+	X           int `json:"x"`
+	Y           int `json:"y"`
+	Orientation int `json:"orientation"`
+}
+
+// Board constrains the square the pieces are packed into. The zero
+// Board searches upwards from the smallest size that could fit.
+type Board struct {
+	// Size fixes the board size
+	Size int `json:"size,omitempty"`
+
+	// MaxSize is the largest size tried. Zero uses the solver default.
+	// A Puzzle encodes it as options.max_size, as in the input format.
+	MaxSize int `json:"-"`
+
+	// Mask fixes the board to a square of rows where '#' marks a
+	// blocked cell and '.' a usable one
+	Mask []string `json:"mask,omitempty"`
+}
+
+// Puzzle is a set of pieces and the board to pack them into. It
+// encodes in JSON as an input document.
+type Puzzle struct {
+	Pieces []Piece `json:"pieces"`
+	Board  Board   `json:"board"`
+}
+
+// Placement is where a piece ended up in a solution
+type Placement struct {
+	ID    string  `json:"id"`
+	Cells []Point `json:"cells"`
+}
+
+// Solution is a solved puzzle
+type Solution struct {
+	// Size is the side of the square board
+	Size int `json:"size"`
+
+	// Grid holds one string per row, with '.' for empty cells, '#' for
+	// blocked cells and piece IDs elsewhere
+	Grid []string `json:"grid"`
+
+	// Placements lists the covered cells of every piece, in input order
+	Placements []Placement `json:"placements"`
+
+	// Nodes is the number of search nodes explored
+	Nodes int `json:"nodes"`
+
+	// Optimal reports that no smaller board can hold the pieces. The
+	// "heuristic" strategy returns unproven solutions with it unset.
+	Optimal bool `json:"optimal"`
+}
+
+// Progress reports a running search
+type Progress struct {
+	Size  int
+	Nodes int
+	Depth int
+}
+
+// Options tunes a solve
+type Options struct {
+	// Progress, when set, is called from the solving goroutine as each
+	// board size starts and then every ProgressEvery nodes
+	Progress func(Progress)
+
+	// ProgressEvery sets how many nodes pass between progress reports.
+	// Zero uses the solver default.
+	ProgressEvery int
+
+	// Strategy names the search algorithm, one of Strategies(). Empty
+	// uses the default.
+	Strategy string
+
+	// Order is the piece ordering heuristic: "input", "hardest",
+	// "fewest-placements" or "dynamic". Empty keeps input order.
+	Order string
+
+	// TimeLimit bounds how long the "heuristic" strategy searches. Zero
+	// uses the solver default.
+	TimeLimit time.Duration
+}
+
+// Strategies returns the names of the available search algorithms
+func Strategies() []string {
+	return solver.Strategies()
+}
+
+// Parse reads a puzzle in the given format
+func Parse(r io.Reader, format Format) (*Puzzle, error) {
+	f, err := parser.ParseFormat(string(format))
+	if err != nil {
+		return nil, err
+	}
+
+	puzzle, err := parser.ParsePuzzle(r, "input", f)
+	if err != nil {
+		return nil, err
+	}
+
+	return fromInternal(puzzle), nil
+}
+
+// Solve packs the puzzle's pieces into the smallest square board. It
+// stops early with ctx.Err() when ctx is cancelled.
+func Solve(ctx context.Context, puzzle *Puzzle, opts Options) (*Solution, error) {
+	internal, err := toInternal(puzzle)
+	if err != nil {
+		return nil, err
+	}
+
+	solverOpts := internal.Options
+	solverOpts.ProgressEvery = opts.ProgressEvery
+	solverOpts.Strategy = opts.Strategy
+	solverOpts.Order = opts.Order
+	solverOpts.TimeLimit = opts.TimeLimit
+	if opts.Progress != nil {
+		solverOpts.Progress = func(p solver.Progress) {
+			opts.Progress(Progress{Size: p.Size, Nodes: p.Nodes, Depth: p.Depth})
+		}
+	}
+
+	result, err := solver.SolveOptimalContext(ctx, internal.Pieces, solverOpts)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Success {
+		return nil, ErrNoSolution
+	}
+
+	solution := newSolution(internal.Pieces, result.Grid, result.Nodes)
+	solution.Optimal = result.Optimal
+	return solution, nil
+}
+
+// Verify checks that grid, one string per row, is a valid packing of
+// every piece of the puzzle
+func Verify(puzzle *Puzzle, rows []string) error {
+	internal, err := toInternal(puzzle)
+	if err != nil {
+		return err
+	}
+
+	g, err := grid.FromRows(rows)
+	if err != nil {
+		return err
+	}
+
+	return solver.Verify(internal.Pieces, g)
+}
+
+// Render returns the solution grid as text, one line per row, in the
+// same form the command-line tool prints
+func Render(solution *Solution) string {
+	var b strings.Builder
+	for _, row := range solution.Grid {
+		b.WriteString(row)
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// MarshalJSON encodes a point as an [x, y] pair
+func (p Point) MarshalJSON() ([]byte, error) {
+	return json.Marshal([2]int{p.X, p.Y})
+}
+
+// UnmarshalJSON decodes an [x, y] pair
+func (p *Point) UnmarshalJSON(data []byte) error {
+	var pair []int
+	if err := json.Unmarshal(data, &pair); err != nil {
+		return err
+	}
+	if len(pair) != 2 {
+		return fmt.Errorf("point must be an [x, y] pair, got %d values", len(pair))
+	}
+	p.X, p.Y = pair[0], pair[1]
+	return nil
+}
+
+// MarshalJSON encodes a puzzle in the JSON input format
+func (p Puzzle) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.document())
+}
+
+// UnmarshalJSON decodes a puzzle from the JSON input format
+func (p *Puzzle) UnmarshalJSON(data []byte) error {
+	var doc inputDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	*p = Puzzle{Pieces: doc.Pieces}
+	if doc.Board != nil {
+		p.Board.Size, p.Board.Mask = doc.Board.Size, doc.Board.Mask
+	}
+	if doc.Options != nil {
+		p.Board.MaxSize = doc.Options.MaxSize
+	}
+	return nil
+}
+
+// inputDocument is the JSON input format a Puzzle is converted to, so
+// public puzzles are validated exactly like JSON files
+type inputDocument struct {
+	Pieces  []Piece       `json:"pieces"`
+	Board   *inputBoard   `json:"board,omitempty"`
+	Options *inputOptions `json:"options,omitempty"`
+}
+
+// inputBoard is the board section of the JSON input format
+type inputBoard struct {
+	Size int      `json:"size,omitempty"`
+	Mask []string `json:"mask,omitempty"`
+}
+
+// inputOptions is the options section of the JSON input format
+type inputOptions struct {
+	MaxSize int `json:"max_size"`
+}
+
+// toInternal validates a puzzle and converts it to parsed tetrominoes
+// and solver options
+func toInternal(puzzle *Puzzle) (*parser.Puzzle, error) {
+	if puzzle == nil {
+		return nil, errors.New("nil puzzle")
+	}
+
+	data, err := json.Marshal(puzzle.document())
+	if err != nil {
+		return nil, err
+	}
+	return parser.ParseJSON(bytes.NewReader(data), "puzzle")
+}
+
+// document converts a puzzle to the JSON input format
+func (p *Puzzle) document() inputDocument {
+	doc := inputDocument{Pieces: p.Pieces}
+	if p.Board.Size != 0 || p.Board.Mask != nil {
+		doc.Board = &inputBoard{Size: p.Board.Size, Mask: p.Board.Mask}
+	}
+	if p.Board.MaxSize != 0 {
+		doc.Options = &inputOptions{MaxSize: p.Board.MaxSize}
+	}
+	return doc
+}
+
+// fromInternal converts parsed tetrominoes back to a public puzzle.
+// Pieces matching a standard tetromino in its reference orientation are
+// given by Kind, everything else by Cells.
+func fromInternal(puzzle *parser.Puzzle) *Puzzle {
+	out := &Puzzle{Pieces: make([]Piece, len(puzzle.Pieces))}
+
+	for i, t := range puzzle.Pieces {
+		piece := Piece{ID: string(t.ID)}
+
+		if standard, err := tetromino.NewStandard(t.ID, t.Kind); err == nil && standard.ShapeKey() == t.ShapeKey() {
+			piece.Kind = t.Kind.String()
+		} else {
+			for _, p := range t.Shape.Cells() {
+				piece.Cells = append(piece.Cells, Point{X: p.X, Y: p.Y})
+			}
+		}
+
+		for _, turns := range t.Rotations {
+			piece.Orientations = append(piece.Orientations, turns*90)
+		}
+		if t.Pin != nil {
+			piece.Pin = &Pin{X: t.Pin.X, Y: t.Pin.Y, Orientation: t.Pin.Rotation * 90}
+		}
+
+		out.Pieces[i] = piece
+	}
+
+	opts := puzzle.Options
+	out.Board = Board{Size: opts.Size, MaxSize: opts.MaxSize}
+	if opts.Mask != nil {
+		out.Board.Mask = make([]string, len(opts.Mask))
+		for y, row := range opts.Mask {
+			cells := make([]byte, len(row))
+			for x, blocked := range row {
+				cells[x] = '.'
+				if blocked {
+					cells[x] = grid.Blocked
+				}
+			}
+			out.Board.Mask[y] = string(cells)
+		}
+	}
+
+	return out
+}
+
+// newSolution describes a solved grid
+func newSolution(pieces []*tetromino.Tetromino, g *grid.Grid, nodes int) *Solution {
+	solution := &Solution{
+		Size:       g.Size,
+		Grid:       strings.Split(strings.TrimSuffix(g.String(), "\n"), "\n"),
+		Placements: make([]Placement, len(pieces)),
+		Nodes:      nodes,
+	}
+
+	index := make(map[rune]int, len(pieces))
+	for i, t := range pieces {
+		index[t.ID] = i
+		solution.Placements[i].ID = string(t.ID)
+	}
+
+	for y, row := range g.Cells {
+		for x, cell := range row {
+			if i, ok := index[cell]; ok {
+				solution.Placements[i].Cells = append(solution.Placements[i].Cells, Point{X: x, Y: y})
+			}
+		}
+	}
+
+	return solution
+}
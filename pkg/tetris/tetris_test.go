@@ -0,0 +1,108 @@
+package tetris_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stkisengese/tetris-optimizer/pkg/tetris"
+)
+
+func TestParseRoundTrip(t *testing.T) {
+	puzzle, err := tetris.Parse(strings.NewReader("I T@90=0,0 O x2"), tetris.FormatCompact)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(puzzle.Pieces) != 4 {
+		t.Fatalf("Expected 4 pieces, got %d", len(puzzle.Pieces))
+	}
+	if p := puzzle.Pieces[0]; p.ID != "A" || p.Kind != "I" {
+		t.Errorf("Expected piece A of kind I, got %+v", p)
+	}
+
+	// The rotated T keeps its exact shape as cells
+	pinned := puzzle.Pieces[1]
+	if pinned.Kind != "" || len(pinned.Cells) != 4 || pinned.Pin == nil {
+		t.Errorf("Expected the pinned T as cells with a pin, got %+v", pinned)
+	}
+
+	solution, err := tetris.Solve(context.Background(), puzzle, tetris.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if solution.Grid[0][0] != 'B' {
+		t.Errorf("Expected the pinned T at the origin, got\n%s", tetris.Render(solution))
+	}
+	if err := tetris.Verify(puzzle, solution.Grid); err != nil {
+		t.Errorf("Expected solution to verify, got %v", err)
+	}
+}
+
+func TestSolvePlacements(t *testing.T) {
+	puzzle := &tetris.Puzzle{Pieces: []tetris.Piece{
+		{ID: "X", Cells: []tetris.Point{{0, 0}, {1, 0}, {0, 1}, {1, 1}}},
+		{Kind: "O"},
+	}}
+
+	solution, err := tetris.Solve(context.Background(), puzzle, tetris.Options{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(solution.Placements) != 2 || solution.Placements[0].ID != "X" || solution.Placements[1].ID != "A" {
+		t.Fatalf("Expected placements X and A, got %+v", solution.Placements)
+	}
+	for _, p := range solution.Placements {
+		if len(p.Cells) != 4 {
+			t.Errorf("Expected 4 cells for %s, got %v", p.ID, p.Cells)
+		}
+	}
+}
+
+func TestSolveErrors(t *testing.T) {
+	_, err := tetris.Solve(context.Background(), &tetris.Puzzle{
+		Pieces: []tetris.Piece{{Kind: "I"}},
+		Board:  tetris.Board{Size: 3},
+	}, tetris.Options{})
+	if !errors.Is(err, tetris.ErrNoSolution) {
+		t.Errorf("Expected ErrNoSolution, got %v", err)
+	}
+
+	_, err = tetris.Solve(context.Background(), &tetris.Puzzle{Pieces: []tetris.Piece{{Kind: "Q"}}}, tetris.Options{})
+	if err == nil || !strings.Contains(err.Error(), "pieces[0].kind") {
+		t.Errorf("Expected error naming pieces[0].kind, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = tetris.Solve(ctx, &tetris.Puzzle{Pieces: []tetris.Piece{{Kind: "O"}}}, tetris.Options{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	puzzle := &tetris.Puzzle{Pieces: []tetris.Piece{{Kind: "O"}, {Kind: "O"}}}
+	if err := tetris.Verify(puzzle, []string{"AA..", "AA..", "....", "...."}); err == nil {
+		t.Error("Expected error for a missing piece, got nil")
+	}
+}
+
+func ExampleSolve() {
+	puzzle := &tetris.Puzzle{Pieces: []tetris.Piece{{Kind: "O"}, {Kind: "O"}, {Kind: "O"}, {Kind: "O"}}}
+
+	solution, err := tetris.Solve(context.Background(), puzzle, tetris.Options{})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Print(tetris.Render(solution))
+	// Output:
+	// AABB
+	// AABB
+	// CCDD
+	// CCDD
+}